@@ -0,0 +1,466 @@
+package checks
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+)
+
+const (
+	defaultSchedulerWorkers   = 100
+	defaultSchedulerMaxChecks = 10000
+	defaultDrainSeconds       = 20
+	defaultCheckpointSeconds  = 10
+)
+
+// CheckScheduler runs the attempts of the scheduled publish checks on a bounded pool of workers,
+// in the order they are due. Once it holds maxChecks checks, scheduling more blocks until some are over,
+// which holds back the consumption of publish events.
+// If configured, the checks scheduled are checkpointed to a file to be resumed after a restart.
+type CheckScheduler struct {
+	mu        sync.Mutex
+	notFull   *sync.Cond
+	queue     checkQueue
+	checks    int // scheduled and not over yet
+	running   int
+	maxChecks int
+	workers   int
+	blocked   int // callers waiting for room to schedule a check
+	handing   int // checks due, taken off the queue and waiting for a free worker
+	wake      chan struct{}
+	ready     chan *checkRun
+	stop      chan struct{}
+	stopOnce  sync.Once
+
+	// shutdown
+	draining     bool          // no more checks are scheduled
+	idle         chan struct{} // closed once no check is left while draining
+	idleClosed   bool
+	drainTimeout time.Duration
+	interrupted  int // checks which could not finish before the end of the drain, and were not checkpointed
+	// cancelled at the end of the drain, which interrupts the checks left
+	interrupt       context.Context
+	interruptChecks context.CancelFunc
+	// closed at the end of the drain if the checks left were checkpointed, so that they report their outcome once resumed
+	suspend chan struct{}
+
+	// checkpoint
+	checkpoint         *checkpointFile // nil if the checks are not checkpointed
+	checkpointInterval time.Duration
+	states             map[*checkRun]CheckState // of the checks scheduled, as of their last attempt
+	saving             sync.Mutex
+	checkpointed       bool // the checks left at the end of the drain were checkpointed, nothing else is
+	log                *logger.UPPLogger
+}
+
+// NewCheckScheduler returns a CheckScheduler configured by cfg. Run must be called for the checks to run.
+func NewCheckScheduler(cfg config.SchedulerConfig, log *logger.UPPLogger) *CheckScheduler {
+	s := &CheckScheduler{
+		maxChecks: cfg.MaxChecks,
+		workers:   cfg.Workers,
+		wake:      make(chan struct{}, 1),
+		ready:     make(chan *checkRun),
+		stop:      make(chan struct{}),
+		idle:      make(chan struct{}),
+		suspend:   make(chan struct{}),
+
+		drainTimeout: time.Duration(cfg.DrainSeconds) * time.Second,
+
+		checkpointInterval: time.Duration(cfg.CheckpointSeconds) * time.Second,
+		states:             make(map[*checkRun]CheckState),
+		log:                log,
+	}
+	if cfg.CheckpointFile != "" {
+		s.checkpoint = &checkpointFile{path: cfg.CheckpointFile}
+	}
+	if s.checkpointInterval <= 0 {
+		s.checkpointInterval = defaultCheckpointSeconds * time.Second
+	}
+	if s.maxChecks <= 0 {
+		s.maxChecks = defaultSchedulerMaxChecks
+	}
+	if s.workers <= 0 {
+		s.workers = defaultSchedulerWorkers
+	}
+	if s.drainTimeout <= 0 {
+		s.drainTimeout = defaultDrainSeconds * time.Second
+	}
+	s.notFull = sync.NewCond(&s.mu)
+	s.interrupt, s.interruptChecks = context.WithCancel(context.Background())
+	return s
+}
+
+// Run starts the workers and hands them the checks as they become due, until Stop is called.
+func (s *CheckScheduler) Run() {
+	for i := 0; i < s.workers; i++ {
+		go s.work()
+	}
+	if s.checkpoint != nil {
+		go s.checkpointPeriodically()
+	}
+
+	for {
+		s.mu.Lock()
+		var run *checkRun
+		wait := time.Duration(-1)
+		if len(s.queue) > 0 {
+			if wait = time.Until(s.queue[0].due); wait <= 0 {
+				run = heap.Pop(&s.queue).(*checkRun)
+				s.handing++
+			}
+		}
+		s.mu.Unlock()
+
+		if run != nil {
+			select {
+			case s.ready <- run:
+			case <-s.stop:
+				return
+			}
+			s.mu.Lock()
+			s.handing--
+			s.mu.Unlock()
+			continue
+		}
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+		select {
+		case <-due:
+		case <-s.wake:
+		case <-s.stop:
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// Stop stops the workers once they are done with their current attempt. The checks still queued are abandoned.
+func (s *CheckScheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		s.mu.Lock()
+		s.notFull.Broadcast()
+		s.mu.Unlock()
+	})
+}
+
+// StopScheduling makes scheduling new checks fail, including for the publishes held back waiting for room,
+// so that the consumption of publish events can stop while the scheduled checks carry on.
+func (s *CheckScheduler) StopScheduling() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.draining = true
+	s.notFull.Broadcast()
+	s.checkIdle()
+}
+
+// Drain stops scheduling new checks and waits for the scheduled ones to be over, for up to the drain timeout
+// or until ctx is done, then stops the scheduler. The checks left by then are interrupted: their calls in
+// progress are cancelled and they report the interrupted outcome straight away.
+// If the checks are checkpointed, the last checkpoint holds the checks left instead, which report nothing
+// until they are resumed after the restart, so that their outcome is reported once.
+// It returns how many checks were interrupted.
+func (s *CheckScheduler) Drain(ctx context.Context) int {
+	s.StopScheduling()
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(ctx, s.drainTimeout)
+	defer cancel()
+
+	select {
+	case <-s.idle:
+		s.saveCheckpoint(nil, true)
+		return 0
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	// the checks are checkpointed before they are interrupted, so that none reports an outcome in between
+	left := s.snapshot()
+	if s.saveCheckpoint(left, true) {
+		s.log.Infof("Checkpointed %d publish checks left, to resume them after the restart", len(left))
+		close(s.suspend)
+	}
+	s.interruptChecks()
+	now := time.Now()
+	for _, run := range s.queue {
+		run.due = now
+	}
+	heap.Init(&s.queue)
+	s.notify()
+	s.mu.Unlock()
+
+	select {
+	case <-s.idle:
+	case <-s.stop:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.interrupted
+}
+
+func (s *CheckScheduler) work() {
+	for {
+		select {
+		case run := <-s.ready:
+			s.mu.Lock()
+			s.running++
+			s.mu.Unlock()
+
+			due, more := run.attempt()
+
+			s.mu.Lock()
+			s.running--
+			if more {
+				s.push(run, due)
+			} else {
+				s.checks--
+				delete(s.states, run)
+				if run.interrupted {
+					s.interrupted++
+				}
+				s.notFull.Signal()
+				s.checkIdle()
+			}
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// schedule queues check, to be attempted straight away, once there is room for it.
+// It returns false without scheduling the check if the scheduler was stopped or started draining in the meantime.
+func (s *CheckScheduler) schedule(
+	ctx context.Context,
+	check PublishCheck,
+	metricContainer *metrics.History,
+	publishEvents *PublishEvents,
+	inFlight *InFlightChecks,
+) bool {
+	s.mu.Lock()
+	if s.checks >= s.maxChecks {
+		s.blocked++
+		for s.checks >= s.maxChecks && !s.stopped() && !s.draining {
+			s.notFull.Wait()
+		}
+		s.blocked--
+	}
+	if s.stopped() || s.draining {
+		s.mu.Unlock()
+		return false
+	}
+	s.checks++
+	s.mu.Unlock()
+
+	run := newCheckRun(ctx, s.interrupt, check, metricContainer, publishEvents, inFlight)
+	run.suspended = s.suspend
+	inFlight.onCancel(run.checkID, func() { s.expedite(run) })
+
+	s.mu.Lock()
+	s.push(run, time.Now())
+	s.mu.Unlock()
+	return true
+}
+
+// push queues run to be attempted at due. Callers must hold the lock.
+func (s *CheckScheduler) push(run *checkRun, due time.Time) {
+	run.due = due
+	select {
+	case <-run.cancelled:
+		run.due = time.Now()
+	case <-s.interrupt.Done():
+		run.due = time.Now()
+	default:
+	}
+	heap.Push(&s.queue, run)
+	if s.checkpoint != nil {
+		s.states[run] = run.state()
+	}
+	s.notify()
+}
+
+// expedite moves run to the front of the queue, so that its cancellation takes effect straight away.
+// If run is being attempted, it is cancelled once the attempt is over.
+func (s *CheckScheduler) expedite(run *checkRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if run.index < 0 {
+		return
+	}
+	run.due = time.Now()
+	heap.Fix(&s.queue, run.index)
+	s.notify()
+}
+
+// notify wakes up Run to reconsider the first check due. Callers must hold the lock.
+func (s *CheckScheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// checkIdle closes idle once no check is left while draining. Callers must hold the lock.
+func (s *CheckScheduler) checkIdle() {
+	if s.draining && s.checks == 0 && !s.idleClosed {
+		close(s.idle)
+		s.idleClosed = true
+	}
+}
+
+// Stopping tells whether the scheduler stopped scheduling new checks.
+func (s *CheckScheduler) Stopping() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.draining || s.stopped()
+}
+
+func (s *CheckScheduler) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Checkpointed returns the checks of the last checkpoint, to be resumed. It returns none if the checks are not checkpointed.
+func (s *CheckScheduler) Checkpointed() ([]CheckState, error) {
+	if s.checkpoint == nil {
+		return []CheckState{}, nil
+	}
+	return s.checkpoint.load()
+}
+
+func (s *CheckScheduler) checkpointPeriodically() {
+	ticker := time.NewTicker(s.checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			states := s.snapshot()
+			s.mu.Unlock()
+			s.saveCheckpoint(states, false)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// snapshot returns the states of the checks scheduled, the oldest publish first. Callers must hold the lock.
+func (s *CheckScheduler) snapshot() []CheckState {
+	states := make([]CheckState, 0, len(s.states))
+	for _, state := range s.states {
+		states = append(states, state)
+	}
+	slices.SortFunc(states, func(a, b CheckState) int {
+		return a.Metric.PublishDate.Compare(b.Metric.PublishDate)
+	})
+	return states
+}
+
+// saveCheckpoint replaces the checkpoint with states, unless the last checkpoint was saved.
+// It returns whether the checkpoint was saved.
+func (s *CheckScheduler) saveCheckpoint(states []CheckState, last bool) bool {
+	if s.checkpoint == nil {
+		return false
+	}
+
+	s.saving.Lock()
+	defer s.saving.Unlock()
+
+	if s.checkpointed {
+		return false
+	}
+	s.checkpointed = last
+
+	if err := s.checkpoint.save(states); err != nil {
+		s.log.WithError(err).Error("Cannot checkpoint the checks in progress")
+		return false
+	}
+	return true
+}
+
+// Queued returns how many checks are waiting for their next attempt.
+func (s *CheckScheduler) Queued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue) + s.handing
+}
+
+// Due returns how many checks are due and waiting for a free worker.
+func (s *CheckScheduler) Due() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	due := s.handing
+	for _, run := range s.queue {
+		if !run.due.After(now) {
+			due++
+		}
+	}
+	return due
+}
+
+// Running returns how many checks are being attempted.
+func (s *CheckScheduler) Running() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Blocked returns how many publishes are held back waiting for room to schedule their checks.
+func (s *CheckScheduler) Blocked() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.blocked
+}
+
+// checkQueue is a min-heap of checks, the first due first.
+type checkQueue []*checkRun
+
+func (q checkQueue) Len() int { return len(q) }
+
+func (q checkQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+
+func (q checkQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *checkQueue) Push(x any) {
+	run := x.(*checkRun)
+	run.index = len(*q)
+	*q = append(*q, run)
+}
+
+func (q *checkQueue) Pop() any {
+	old := *q
+	n := len(old)
+	run := old[n-1]
+	old[n-1] = nil
+	run.index = -1
+	*q = old[:n-1]
+	return run
+}
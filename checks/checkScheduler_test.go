@@ -0,0 +1,334 @@
+package checks
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/envs"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSchedulerBoundsConcurrentAttempts(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 10)
+	endpointCheck := &concurrencyCheck{release: make(chan struct{})}
+
+	scheduler := NewCheckScheduler(config.SchedulerConfig{Workers: 2}, log)
+	go scheduler.Run()
+	defer scheduler.Stop()
+
+	for i := 0; i < 5; i++ {
+		pm := metrics.PublishMetric{
+			UUID:        "uuid-1",
+			PublishDate: time.Now(),
+			Config:      config.MetricConfig{Alias: "content"},
+		}
+		check := NewPublishCheck(pm, "", "", 60, 1, metricSink, map[string]EndpointSpecificCheck{"content": endpointCheck}, log)
+		require.True(t, scheduler.schedule(context.Background(), *check, metrics.NewHistory(nil), nil, nil))
+	}
+
+	require.Eventually(t, func() bool {
+		return scheduler.Running() == 2 && scheduler.Due() == 3
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, scheduler.Queued())
+
+	close(endpointCheck.release)
+	require.Eventually(t, func() bool {
+		return len(metricSink) == 5
+	}, time.Second, 10*time.Millisecond)
+	endpointCheck.mu.Lock()
+	assert.Equal(t, 2, endpointCheck.maxRunning)
+	endpointCheck.mu.Unlock()
+	assert.Zero(t, scheduler.Running())
+	assert.Zero(t, scheduler.Queued())
+}
+
+func TestCheckSchedulerHoldsBackChecksWhenFull(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 10)
+
+	scheduler := NewCheckScheduler(config.SchedulerConfig{Workers: 1, MaxChecks: 1}, log)
+	go scheduler.Run()
+	defer scheduler.Stop()
+
+	newCheck := func(threshold int) PublishCheck {
+		pm := metrics.PublishMetric{
+			UUID:        "uuid-1",
+			PublishDate: time.Now(),
+			Config:      config.MetricConfig{Alias: "content"},
+		}
+		return *NewPublishCheck(pm, "", "", threshold, 1, metricSink, map[string]EndpointSpecificCheck{"content": neverFinishedCheck{}}, log)
+	}
+
+	require.True(t, scheduler.schedule(context.Background(), newCheck(2), metrics.NewHistory(nil), nil, nil))
+
+	scheduled := make(chan bool)
+	go func() {
+		scheduled <- scheduler.schedule(context.Background(), newCheck(2), metrics.NewHistory(nil), nil, nil)
+	}()
+
+	require.Eventually(t, func() bool {
+		return scheduler.Blocked() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case ok := <-scheduled:
+		assert.True(t, ok)
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "check was not scheduled once the first one was over")
+	}
+	assert.Zero(t, scheduler.Blocked())
+	assert.Equal(t, metrics.OutcomeFailure, receiveMetric(t, metricSink).Outcome)
+}
+
+func TestCheckSchedulerStopReleasesBlockedChecks(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	scheduler := NewCheckScheduler(config.SchedulerConfig{MaxChecks: 1}, log)
+	go scheduler.Run()
+
+	pm := metrics.PublishMetric{PublishDate: time.Now(), Config: config.MetricConfig{Alias: "content"}}
+	check := NewPublishCheck(pm, "", "", 60, 1, make(chan metrics.PublishMetric, 1), map[string]EndpointSpecificCheck{"content": neverFinishedCheck{}}, log)
+	require.True(t, scheduler.schedule(context.Background(), *check, metrics.NewHistory(nil), nil, nil))
+
+	scheduled := make(chan bool)
+	go func() {
+		scheduled <- scheduler.schedule(context.Background(), *check, metrics.NewHistory(nil), nil, nil)
+	}()
+	require.Eventually(t, func() bool {
+		return scheduler.Blocked() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	scheduler.Stop()
+	assert.False(t, <-scheduled)
+}
+
+func TestCheckSchedulerStopSchedulingReleasesBlockedChecks(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	scheduler := NewCheckScheduler(config.SchedulerConfig{MaxChecks: 1}, log)
+	go scheduler.Run()
+	defer scheduler.Stop()
+
+	pm := metrics.PublishMetric{PublishDate: time.Now(), Config: config.MetricConfig{Alias: "content"}}
+	check := NewPublishCheck(pm, "", "", 60, 1, make(chan metrics.PublishMetric, 1), map[string]EndpointSpecificCheck{"content": neverFinishedCheck{}}, log)
+	require.True(t, scheduler.schedule(context.Background(), *check, metrics.NewHistory(nil), nil, nil))
+
+	scheduled := make(chan bool)
+	go func() {
+		scheduled <- scheduler.schedule(context.Background(), *check, metrics.NewHistory(nil), nil, nil)
+	}()
+	require.Eventually(t, func() bool {
+		return scheduler.Blocked() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	scheduler.StopScheduling()
+	assert.False(t, <-scheduled)
+	assert.False(t, scheduler.schedule(context.Background(), *check, metrics.NewHistory(nil), nil, nil))
+}
+
+func TestCheckSchedulerDrainWaitsForScheduledChecks(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 1)
+	endpointCheck := &concurrencyCheck{release: make(chan struct{})}
+
+	scheduler := NewCheckScheduler(config.SchedulerConfig{}, log)
+	go scheduler.Run()
+
+	pm := metrics.PublishMetric{PublishDate: time.Now(), Config: config.MetricConfig{Alias: "content"}}
+	check := NewPublishCheck(pm, "", "", 60, 1, metricSink, map[string]EndpointSpecificCheck{"content": endpointCheck}, log)
+	require.True(t, scheduler.schedule(context.Background(), *check, metrics.NewHistory(nil), nil, nil))
+
+	drained := make(chan int)
+	go func() {
+		drained <- scheduler.Drain(context.Background())
+	}()
+
+	select {
+	case <-drained:
+		require.Fail(t, "drain should wait for the check in progress")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(endpointCheck.release)
+	assert.Zero(t, <-drained)
+	assert.Equal(t, metrics.OutcomeSuccess, receiveMetric(t, metricSink).Outcome)
+	assert.True(t, scheduler.stopped())
+}
+
+func TestCheckSchedulerDrainInterruptsChecksLeft(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 2)
+	history := metrics.NewHistory(nil)
+	publishEvents := NewPublishEvents(10)
+
+	scheduler := NewCheckScheduler(config.SchedulerConfig{}, log)
+	go scheduler.Run()
+
+	// one check is waiting for its next attempt, the other one for the response of the endpoint
+	queued := metrics.PublishMetric{TID: "tid_1", PublishDate: time.Now(), Platform: "eu", Config: config.MetricConfig{Alias: "content"}}
+	running := metrics.PublishMetric{TID: "tid_1", PublishDate: time.Now(), Platform: "eu", Config: config.MetricConfig{Alias: "notifications"}}
+	endpointChecks := map[string]EndpointSpecificCheck{"content": neverFinishedCheck{}, "notifications": untilCancelledCheck{}}
+	for _, pm := range []metrics.PublishMetric{queued, running} {
+		publishEvents.scheduled(pm, "article")
+		check := NewPublishCheck(pm, "", "", 60, 30, metricSink, endpointChecks, log)
+		require.True(t, scheduler.schedule(context.Background(), *check, history, publishEvents, NewInFlightChecks()))
+	}
+	require.Eventually(t, func() bool {
+		return scheduler.Running() == 1 && scheduler.Queued() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, 2, scheduler.Drain(ctx))
+
+	for i := 0; i < 2; i++ {
+		pm := receiveMetric(t, metricSink)
+		assert.Equal(t, metrics.OutcomeInterrupted, pm.Outcome)
+		assert.False(t, pm.PublishOK)
+	}
+	assert.Len(t, history.Query(metrics.HistoryQuery{Outcome: metrics.OutcomeInterrupted}).PublishMetrics, 2)
+
+	event, found := publishEvents.Get("tid_1")
+	require.True(t, found)
+	for _, c := range event.Checks {
+		assert.Equal(t, CheckInterrupted, c.Status, c.EndpointAlias)
+	}
+	assert.Equal(t, PublishInconclusive, event.Status)
+}
+
+func TestCheckSchedulerCheckpointsScheduledChecks(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 1)
+
+	scheduler := NewCheckScheduler(config.SchedulerConfig{CheckpointFile: filepath.Join(t.TempDir(), "checkpoint.log")}, log)
+	scheduler.checkpointInterval = 20 * time.Millisecond
+	go scheduler.Run()
+
+	pm := metrics.PublishMetric{TID: "tid_1", PublishDate: time.Now(), Platform: "eu", Config: config.MetricConfig{Alias: "content"}}
+	check := NewPublishCheck(pm, "", "", 60, 30, metricSink, map[string]EndpointSpecificCheck{"content": neverFinishedCheck{}}, log)
+	require.True(t, scheduler.schedule(context.Background(), *check, metrics.NewHistory(nil), nil, NewInFlightChecks()))
+
+	require.Eventually(t, func() bool {
+		states, err := scheduler.Checkpointed()
+		return err == nil && len(states) == 1 && len(states[0].Metric.Attempts) == 1
+	}, time.Second, 10*time.Millisecond, "the check should be checkpointed after its first attempt")
+	states, err := scheduler.Checkpointed()
+	require.NoError(t, err)
+	assert.Equal(t, "tid_1", states[0].Metric.TID)
+	assert.Equal(t, 60, states[0].Threshold)
+	assert.Equal(t, 30, states[0].CheckInterval)
+	assert.False(t, states[0].LateTracking)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Zero(t, scheduler.Drain(ctx), "the checks left in the checkpoint should not be interrupted")
+	assert.Empty(t, metricSink)
+
+	states, err = scheduler.Checkpointed()
+	require.NoError(t, err)
+	require.Len(t, states, 1, "the checks left should be in the checkpoint")
+	assert.Equal(t, "tid_1", states[0].Metric.TID)
+}
+
+func TestCheckSchedulerResumesChecksLeftByDrain(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 2)
+	history := metrics.NewHistory(nil)
+	cfg := config.SchedulerConfig{CheckpointFile: filepath.Join(t.TempDir(), "checkpoint.log")}
+
+	// one check is waiting for its next attempt, the other one for the response of the endpoint
+	queued := metrics.PublishMetric{TID: "tid_1", PublishDate: time.Now(), Platform: "eu", Config: config.MetricConfig{Alias: "content"}}
+	running := metrics.PublishMetric{TID: "tid_2", PublishDate: time.Now(), Platform: "eu", Config: config.MetricConfig{Alias: "notifications"}}
+
+	before := NewCheckScheduler(cfg, log)
+	go before.Run()
+	endpointChecks := map[string]EndpointSpecificCheck{"content": neverFinishedCheck{}, "notifications": untilCancelledCheck{}}
+	for _, pm := range []metrics.PublishMetric{queued, running} {
+		check := NewPublishCheck(pm, "", "", 60, 30, metricSink, endpointChecks, log)
+		require.True(t, before.schedule(context.Background(), *check, history, NewPublishEvents(10), NewInFlightChecks()))
+	}
+	require.Eventually(t, func() bool {
+		return before.Running() == 1 && before.Queued() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Zero(t, before.Drain(ctx))
+
+	after := NewCheckScheduler(cfg, log)
+	states, err := after.Checkpointed()
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+	go after.Run()
+	t.Cleanup(after.Stop)
+
+	environments := envs.NewEnvironments()
+	environments.SetEnvironment("eu", envs.Environment{Name: "eu", ReadURL: "https://eu.ft.com"})
+	appConfig := &config.AppConfig{
+		MetricConf: []config.MetricConfig{{Alias: "content", Granularity: 1}, {Alias: "notifications", Granularity: 1}},
+	}
+	available := &concurrencyCheck{release: make(chan struct{})}
+	close(available.release)
+	endpointChecks = map[string]EndpointSpecificCheck{"content": available, "notifications": available}
+	resumed := ResumeChecks(context.Background(), states, endpointChecks, appConfig, environments, SchedulerDeps{
+		MetricSink:    metricSink,
+		History:       history,
+		PublishEvents: NewPublishEvents(10),
+		InFlight:      NewInFlightChecks(),
+		Scheduler:     after,
+	}, log)
+	require.Equal(t, 2, resumed)
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, metrics.OutcomeSuccess, receiveMetric(t, metricSink).Outcome, "each check should report its outcome once")
+	}
+	assert.Empty(t, metricSink)
+	assert.Len(t, history.Query(metrics.HistoryQuery{}).PublishMetrics, 2)
+	assert.Empty(t, history.Query(metrics.HistoryQuery{Outcome: metrics.OutcomeInterrupted}).PublishMetrics)
+}
+
+// startScheduler returns a running CheckScheduler, stopped at the end of the test.
+func startScheduler(t *testing.T) *CheckScheduler {
+	scheduler := NewCheckScheduler(config.SchedulerConfig{}, logger.NewUPPLogger("test", "PANIC"))
+	go scheduler.Run()
+	t.Cleanup(scheduler.Stop)
+	return scheduler
+}
+
+// concurrencyCheck blocks until released and records how many checks ran at the same time.
+type concurrencyCheck struct {
+	mu         sync.Mutex
+	running    int
+	maxRunning int
+	release    chan struct{}
+}
+
+func (c *concurrencyCheck) isCurrentOperationFinished(_ context.Context, _ *PublishCheck) (operationFinished, ignoreCheck bool) {
+	c.mu.Lock()
+	c.running++
+	if c.running > c.maxRunning {
+		c.maxRunning = c.running
+	}
+	c.mu.Unlock()
+
+	<-c.release
+
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+	return true, false
+}
+
+// untilCancelledCheck waits for the endpoint until the check is cancelled.
+type untilCancelledCheck struct{}
+
+func (untilCancelledCheck) isCurrentOperationFinished(ctx context.Context, _ *PublishCheck) (operationFinished, ignoreCheck bool) {
+	<-ctx.Done()
+	return false, false
+}
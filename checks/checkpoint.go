@@ -0,0 +1,56 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Financial-Times/publish-availability-monitor/jsonl"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+)
+
+// CheckState is what is checkpointed of a scheduled check, to resume it after a restart.
+// The metric carries the last attempts of the check.
+type CheckState struct {
+	Metric            metrics.PublishMetric `json:"metric"`
+	Threshold         int                   `json:"threshold"`
+	CheckInterval     int                   `json:"checkInterval"`
+	HardLimit         int                   `json:"hardLimit,omitempty"`
+	LateCheckInterval int                   `json:"lateCheckInterval,omitempty"`
+	LateTracking      bool                  `json:"lateTracking,omitempty"` // the failure of the check was already reported
+}
+
+// checkpointFile holds the state of the checks in progress as JSON encoded CheckStates, one per line.
+type checkpointFile struct {
+	mu   sync.Mutex
+	path string
+}
+
+// load reads the check states from the file.
+// Lines which cannot be decoded are skipped, and a missing file holds no check.
+func (f *checkpointFile) load() ([]CheckState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := jsonl.ReadFile[CheckState](f.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load checkpoint: %w", err)
+	}
+	return states, nil
+}
+
+// save atomically replaces the file contents with states.
+// The file and its parent directories are created if missing.
+func (f *checkpointFile) save(states []CheckState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("cannot create checkpoint directory: %w", err)
+	}
+	if err := jsonl.WriteFile(f.path, states); err != nil {
+		return fmt.Errorf("cannot save checkpoint: %w", err)
+	}
+	return nil
+}
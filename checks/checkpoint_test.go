@@ -0,0 +1,57 @@
+package checks
+
+import (
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointFileSavesCheckStates(t *testing.T) {
+	f := &checkpointFile{path: filepath.Join(t.TempDir(), "pam", "checkpoint.log")}
+
+	states, err := f.load()
+	require.NoError(t, err)
+	assert.Empty(t, states, "a missing checkpoint should hold no check")
+
+	endpoint, err := url.Parse("https://eu.ft.com/content/")
+	require.NoError(t, err)
+	saved := []CheckState{
+		{
+			Metric: metrics.PublishMetric{
+				UUID:        "077f5ac2-0491-420e-a5d0-982e0f86204b",
+				TID:         "tid_1",
+				ContentType: "application/vnd.ft-upp-article-internal+json",
+				PublishDate: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+				Platform:    "eu",
+				Outcome:     metrics.OutcomeFailure,
+				Config:      config.MetricConfig{Alias: "content", Endpoint: "/content/", APIKey: "secret"},
+				Endpoint:    *endpoint,
+				Attempts:    []metrics.CheckAttempt{{At: time.Date(2023, 10, 1, 12, 2, 0, 0, time.UTC), StatusCode: 404}},
+			},
+			Threshold:         120,
+			CheckInterval:     12,
+			HardLimit:         600,
+			LateCheckInterval: 30,
+			LateTracking:      true,
+		},
+	}
+	require.NoError(t, f.save(saved))
+
+	states, err = f.load()
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Empty(t, states[0].Metric.Config.APIKey, "API keys should not be written out")
+	saved[0].Metric.Config.APIKey = ""
+	assert.Equal(t, saved, states)
+
+	require.NoError(t, f.save(nil))
+	states, err = f.load()
+	require.NoError(t, err)
+	assert.Empty(t, states)
+}
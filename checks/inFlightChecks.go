@@ -0,0 +1,181 @@
+package checks
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/google/uuid"
+)
+
+// CheckCancelled is the status of a check stopped through InFlightChecks before it completed.
+const CheckCancelled CheckStatus = "cancelled"
+
+// InFlightCheck describes a check which is currently polling an endpoint.
+type InFlightCheck struct {
+	ID              string `json:"id"`
+	EndpointAlias   string `json:"endpoint"`
+	UUID            string `json:"uuid"`
+	Environment     string `json:"environment"`
+	TID             string `json:"transactionId"`
+	SecondsUntilSLA int    `json:"secondsUntilSLA"`
+	Attempts        int    `json:"attempts"`
+}
+
+type inFlightEntry struct {
+	check       InFlightCheck
+	slaDeadline time.Time
+	cancelled   chan struct{}
+	cancelOnce  sync.Once
+	onCancel    func()
+}
+
+// cancel closes the cancelled channel and calls onCancel. Callers must hold the lock of the registry.
+func (e *inFlightEntry) cancel() {
+	e.cancelOnce.Do(func() {
+		close(e.cancelled)
+		if e.onCancel != nil {
+			e.onCancel()
+		}
+	})
+}
+
+// InFlightChecks is a registry of the checks currently polling endpoints, which allows cancelling them.
+// A nil *InFlightChecks is valid and tracks nothing.
+type InFlightChecks struct {
+	mu     sync.RWMutex
+	checks map[string]*inFlightEntry
+}
+
+func NewInFlightChecks() *InFlightChecks {
+	return &InFlightChecks{
+		checks: make(map[string]*inFlightEntry),
+	}
+}
+
+// add registers the check producing pm. The returned channel is closed if the check gets cancelled.
+func (c *InFlightChecks) add(pm metrics.PublishMetric, slaDeadline time.Time) (string, <-chan struct{}) {
+	if c == nil {
+		return "", nil
+	}
+
+	entry := &inFlightEntry{
+		check: InFlightCheck{
+			ID:            uuid.NewString(),
+			EndpointAlias: pm.Config.Alias,
+			UUID:          pm.UUID,
+			Environment:   pm.Platform,
+			TID:           pm.TID,
+		},
+		slaDeadline: slaDeadline,
+		cancelled:   make(chan struct{}),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[entry.check.ID] = entry
+
+	return entry.check.ID, entry.cancelled
+}
+
+// onCancel has f called when the check with the given ID gets cancelled, straight away if it already was.
+// f is called with the lock of the registry held, so it must not call InFlightChecks.
+func (c *InFlightChecks) onCancel(id string, f func()) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, found := c.checks[id]
+	if !found {
+		return
+	}
+	select {
+	case <-entry.cancelled:
+		f()
+	default:
+		entry.onCancel = f
+	}
+}
+
+// attempted counts one more attempt of the check with the given ID.
+func (c *InFlightChecks) attempted(id string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, found := c.checks[id]; found {
+		entry.check.Attempts++
+	}
+}
+
+func (c *InFlightChecks) remove(id string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.checks, id)
+}
+
+// List returns the checks in flight, the closest to their SLA first.
+func (c *InFlightChecks) List() []InFlightCheck {
+	if c == nil {
+		return []InFlightCheck{}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	list := make([]InFlightCheck, 0, len(c.checks))
+	for _, entry := range c.checks {
+		check := entry.check
+		check.SecondsUntilSLA = int(time.Until(entry.slaDeadline).Seconds())
+		list = append(list, check)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].SecondsUntilSLA < list[j].SecondsUntilSLA
+	})
+	return list
+}
+
+// Cancel stops the check with the given ID. It returns false if there is no such check in flight.
+func (c *InFlightChecks) Cancel(id string) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, found := c.checks[id]
+	if found {
+		entry.cancel()
+	}
+	return found
+}
+
+// CancelUUID stops every check in flight for the content with the given UUID and returns how many were cancelled.
+func (c *InFlightChecks) CancelUUID(contentUUID string) int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	for _, entry := range c.checks {
+		if entry.check.UUID == contentUUID {
+			entry.cancel()
+			count++
+		}
+	}
+	return count
+}
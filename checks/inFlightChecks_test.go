@@ -0,0 +1,106 @@
+package checks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlightChecksRegistry(t *testing.T) {
+	inFlight := NewInFlightChecks()
+
+	idLater, _ := inFlight.add(metrics.PublishMetric{UUID: "uuid-1", TID: "tid_1", Platform: "eu", Config: config.MetricConfig{Alias: "content"}}, time.Now().Add(time.Minute))
+	idSooner, cancelledSooner := inFlight.add(metrics.PublishMetric{UUID: "uuid-2", TID: "tid_2", Platform: "eu", Config: config.MetricConfig{Alias: "content"}}, time.Now().Add(10*time.Second))
+	inFlight.attempted(idSooner)
+	inFlight.attempted(idSooner)
+
+	list := inFlight.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, idSooner, list[0].ID)
+	assert.Equal(t, "uuid-2", list[0].UUID)
+	assert.Equal(t, 2, list[0].Attempts)
+	assert.InDelta(t, 10, list[0].SecondsUntilSLA, 1)
+	assert.Equal(t, idLater, list[1].ID)
+
+	assert.False(t, inFlight.Cancel("unknown"))
+	assert.True(t, inFlight.Cancel(idSooner))
+	assert.True(t, inFlight.Cancel(idSooner), "cancelling twice should be harmless")
+	select {
+	case <-cancelledSooner:
+	default:
+		t.Fatal("expected check to be cancelled")
+	}
+
+	inFlight.remove(idSooner)
+	assert.Len(t, inFlight.List(), 1)
+}
+
+func TestInFlightChecksCancelUUID(t *testing.T) {
+	inFlight := NewInFlightChecks()
+
+	_, cancelledEU := inFlight.add(metrics.PublishMetric{UUID: "uuid-1", Platform: "eu"}, time.Now())
+	_, cancelledUS := inFlight.add(metrics.PublishMetric{UUID: "uuid-1", Platform: "us"}, time.Now())
+	_, cancelledOther := inFlight.add(metrics.PublishMetric{UUID: "uuid-2", Platform: "eu"}, time.Now())
+
+	assert.Equal(t, 2, inFlight.CancelUUID("uuid-1"))
+
+	assert.True(t, isClosed(cancelledEU))
+	assert.True(t, isClosed(cancelledUS))
+	assert.False(t, isClosed(cancelledOther))
+}
+
+func TestScheduleCheckStopsWhenCancelled(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	inFlight := NewInFlightChecks()
+	publishEvents := NewPublishEvents(10)
+	metricSink := make(chan metrics.PublishMetric, 1)
+	history := metrics.NewHistory(make([]metrics.PublishMetric, 0))
+
+	pm := metrics.PublishMetric{
+		UUID:        "uuid-1",
+		TID:         "tid_1",
+		PublishDate: time.Now(),
+		Platform:    "eu",
+		Config:      config.MetricConfig{Alias: "content"},
+	}
+	check := NewPublishCheck(pm, "", "", 60, 1, metricSink, map[string]EndpointSpecificCheck{"content": neverFinishedCheck{}}, log)
+	publishEvents.scheduled(pm, "")
+
+	scheduler := startScheduler(t)
+	require.True(t, scheduler.schedule(context.Background(), *check, history, publishEvents, inFlight))
+
+	require.Eventually(t, func() bool {
+		return len(inFlight.List()) == 1 && inFlight.List()[0].Attempts == 1
+	}, time.Second, 10*time.Millisecond)
+	require.True(t, inFlight.Cancel(inFlight.List()[0].ID))
+
+	require.Eventually(t, func() bool {
+		return len(inFlight.List()) == 0 && scheduler.Queued() == 0
+	}, time.Second, 10*time.Millisecond, "check was not stopped")
+
+	assert.Empty(t, metricSink, "cancelled checks should not produce metrics")
+	event, _ := publishEvents.Get("tid_1")
+	assert.Equal(t, CheckCancelled, event.Checks[0].Status)
+	assert.Equal(t, PublishInconclusive, event.Status, "a publish whose checks were cancelled is not known to have succeeded")
+}
+
+type neverFinishedCheck struct{}
+
+func (neverFinishedCheck) isCurrentOperationFinished(_ context.Context, _ *PublishCheck) (operationFinished, ignoreCheck bool) {
+	return false, false
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
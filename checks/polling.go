@@ -0,0 +1,162 @@
+package checks
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+)
+
+const (
+	PollingFixed       = "fixed"
+	PollingExponential = "exponential"
+	PollingFrontloaded = "frontloaded"
+
+	defaultPollingInitialInterval = 1
+	defaultPollingMultiplier      = 2
+	defaultPollingMinSamples      = 5
+)
+
+var defaultPollingPercentiles = []float64{10, 25, 50, 75, 90, 95}
+
+// PollingStrategy decides when an endpoint is checked for a publish.
+// Checks are scheduled in seconds since the publish, the first one being the smallest after 0.
+type PollingStrategy interface {
+	// Next returns when the check following the one at previous should run. It must be after previous.
+	Next(previous int) int
+}
+
+// FixedPolling checks at a regular interval.
+type FixedPolling struct {
+	Interval int
+}
+
+func (p FixedPolling) Next(previous int) int {
+	return previous + p.Interval
+}
+
+// ExponentialPolling checks at an interval growing by Multiplier from Initial up to Max,
+// measuring the latency of fast publishes precisely without polling slow ones as often.
+type ExponentialPolling struct {
+	Initial    int
+	Multiplier float64
+	Max        int
+}
+
+func (p ExponentialPolling) Next(previous int) int {
+	interval := int(math.Round(float64(previous) * (p.Multiplier - 1)))
+	if interval < p.Initial {
+		interval = p.Initial
+	}
+	if interval > p.Max {
+		interval = p.Max
+	}
+	return previous + interval
+}
+
+// FrontloadedPolling checks at the given offsets, then at a regular interval.
+type FrontloadedPolling struct {
+	Offsets  []int // sorted
+	Interval int
+}
+
+func (p FrontloadedPolling) Next(previous int) int {
+	i := sort.SearchInts(p.Offsets, previous+1)
+	if i < len(p.Offsets) {
+		return p.Offsets[i]
+	}
+	return previous + p.Interval
+}
+
+// newPollingStrategy returns the polling strategy configured for metric, defaulting to checks every interval seconds.
+// The front-loaded strategy checks at the percentiles of the latencies of the last successful publishes at the endpoint,
+// falling back to fixed polling until there are enough of them in the history.
+func newPollingStrategy(metric config.MetricConfig, interval int, history *metrics.History) PollingStrategy {
+	cfg := metric.Polling
+	if cfg == nil {
+		return FixedPolling{Interval: interval}
+	}
+
+	switch cfg.Strategy {
+	case PollingExponential:
+		p := ExponentialPolling{
+			Initial:    cfg.InitialIntervalSeconds,
+			Multiplier: cfg.Multiplier,
+			Max:        cfg.MaxIntervalSeconds,
+		}
+		if p.Initial <= 0 {
+			p.Initial = defaultPollingInitialInterval
+		}
+		if p.Multiplier <= 1 {
+			p.Multiplier = defaultPollingMultiplier
+		}
+		if p.Max <= 0 {
+			p.Max = interval
+		}
+		return p
+	case PollingFrontloaded:
+		return FrontloadedPolling{
+			Offsets:  latencyPercentiles(metric, cfg, history),
+			Interval: interval,
+		}
+	default:
+		return FixedPolling{Interval: interval}
+	}
+}
+
+// latencySeconds returns the seconds the publish took to become available at the endpoint, rounded up,
+// or the lower bound of its publish interval if its latency was not recorded.
+// The upper bound is when the endpoint was checked, not when the publish became available.
+func latencySeconds(pm metrics.PublishMetric) int {
+	if latency, ok := pm.Latency(); ok {
+		return int(math.Ceil(latency.Seconds()))
+	}
+	return pm.PublishInterval.LowerBound
+}
+
+// latencyPercentiles returns the distinct percentiles of the latencies of the successful publishes at the metric endpoint.
+func latencyPercentiles(metric config.MetricConfig, cfg *config.PollingConfig, history *metrics.History) []int {
+	if history == nil {
+		return nil
+	}
+
+	publishOK := true
+	page := history.Query(metrics.HistoryQuery{EndpointAlias: metric.Alias, PublishOK: &publishOK})
+
+	minSamples := cfg.MinSamples
+	if minSamples <= 0 {
+		minSamples = defaultPollingMinSamples
+	}
+	if len(page.PublishMetrics) < minSamples {
+		return nil
+	}
+
+	latencies := make([]int, 0, len(page.PublishMetrics))
+	for _, pm := range page.PublishMetrics {
+		latencies = append(latencies, latencySeconds(pm))
+	}
+	sort.Ints(latencies)
+
+	percentiles := append([]float64(nil), cfg.Percentiles...)
+	if len(percentiles) == 0 {
+		percentiles = defaultPollingPercentiles
+	}
+	sort.Float64s(percentiles)
+
+	var offsets []int
+	for _, p := range percentiles {
+		rank := int(math.Ceil(p / 100 * float64(len(latencies))))
+		if rank < 1 {
+			rank = 1
+		}
+		if rank > len(latencies) {
+			rank = len(latencies)
+		}
+		offset := latencies[rank-1]
+		if offset > 0 && (len(offsets) == 0 || offset > offsets[len(offsets)-1]) {
+			offsets = append(offsets, offset)
+		}
+	}
+	return offsets
+}
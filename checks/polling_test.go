@@ -0,0 +1,151 @@
+package checks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pollingSchedule(p PollingStrategy, n int) []int {
+	var schedule []int
+	previous := 0
+	for i := 0; i < n; i++ {
+		previous = p.Next(previous)
+		schedule = append(schedule, previous)
+	}
+	return schedule
+}
+
+func TestPollingStrategies(t *testing.T) {
+	tests := map[string]struct {
+		Strategy         PollingStrategy
+		ExpectedSchedule []int
+	}{
+		"fixed": {
+			Strategy:         FixedPolling{Interval: 3},
+			ExpectedSchedule: []int{3, 6, 9, 12, 15, 18},
+		},
+		"exponential": {
+			Strategy:         ExponentialPolling{Initial: 1, Multiplier: 2, Max: 10},
+			ExpectedSchedule: []int{1, 2, 4, 8, 16, 26},
+		},
+		"slow exponential": {
+			Strategy:         ExponentialPolling{Initial: 2, Multiplier: 1.5, Max: 30},
+			ExpectedSchedule: []int{2, 4, 6, 9, 14, 21},
+		},
+		"frontloaded": {
+			Strategy:         FrontloadedPolling{Offsets: []int{2, 3, 5}, Interval: 10},
+			ExpectedSchedule: []int{2, 3, 5, 15, 25, 35},
+		},
+		"frontloaded without history": {
+			Strategy:         FrontloadedPolling{Interval: 10},
+			ExpectedSchedule: []int{10, 20, 30, 40, 50, 60},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedSchedule, pollingSchedule(test.Strategy, 6))
+		})
+	}
+}
+
+func TestNewPollingStrategy(t *testing.T) {
+	// the publishes were available within the buckets of the polling, at the latencies recorded
+	publishDate := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var history []metrics.PublishMetric
+	for _, latency := range []time.Duration{3500, 1200, 7100, 1800, 2300, 4200, 5600, 2900, 3100} {
+		history = append(history, metrics.PublishMetric{
+			PublishOK:       true,
+			PublishDate:     publishDate,
+			Config:          config.MetricConfig{Alias: "content"},
+			PublishInterval: metrics.Interval{UpperBound: 10},
+			Observation:     metrics.Observation{ObservedAt: publishDate.Add(latency * time.Millisecond)},
+		})
+	}
+	// without a recorded latency, the publish was not available before the lower bound
+	history = append(history, metrics.PublishMetric{
+		PublishOK:       true,
+		Config:          config.MetricConfig{Alias: "content"},
+		PublishInterval: metrics.Interval{LowerBound: 29, UpperBound: 30},
+	})
+	history = append(history, metrics.PublishMetric{
+		Config:          config.MetricConfig{Alias: "content"},
+		PublishInterval: metrics.Interval{UpperBound: 120},
+	})
+	publishHistory := metrics.NewHistory(history)
+
+	tests := map[string]struct {
+		Metric           config.MetricConfig
+		ExpectedStrategy PollingStrategy
+	}{
+		"default": {
+			Metric:           config.MetricConfig{Alias: "content"},
+			ExpectedStrategy: FixedPolling{Interval: 3},
+		},
+		"exponential defaults": {
+			Metric:           config.MetricConfig{Alias: "content", Polling: &config.PollingConfig{Strategy: PollingExponential}},
+			ExpectedStrategy: ExponentialPolling{Initial: 1, Multiplier: 2, Max: 3},
+		},
+		"frontloaded": {
+			Metric:           config.MetricConfig{Alias: "content", Polling: &config.PollingConfig{Strategy: PollingFrontloaded}},
+			ExpectedStrategy: FrontloadedPolling{Offsets: []int{2, 3, 4, 6, 8, 29}, Interval: 3},
+		},
+		"frontloaded with custom percentiles": {
+			Metric:           config.MetricConfig{Alias: "content", Polling: &config.PollingConfig{Strategy: PollingFrontloaded, Percentiles: []float64{99, 50}}},
+			ExpectedStrategy: FrontloadedPolling{Offsets: []int{4, 29}, Interval: 3},
+		},
+		"frontloaded without enough history": {
+			Metric:           config.MetricConfig{Alias: "lists", Polling: &config.PollingConfig{Strategy: PollingFrontloaded}},
+			ExpectedStrategy: FrontloadedPolling{Interval: 3},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedStrategy, newPollingStrategy(test.Metric, 3, publishHistory))
+		})
+	}
+}
+
+func TestScheduleCheckReportsIntervalOfPollingStrategy(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 1)
+
+	pm := metrics.PublishMetric{
+		UUID:        "uuid-1",
+		TID:         "tid_1",
+		PublishDate: time.Now().Add(-3 * time.Second),
+		Platform:    "eu",
+		Config:      config.MetricConfig{Alias: "content"},
+	}
+	check := NewPublishCheck(pm, "", "", 60, 10, metricSink, map[string]EndpointSpecificCheck{"content": &finishedAfterCheck{attempts: 2}}, log)
+	check.Polling = ExponentialPolling{Initial: 1, Multiplier: 2, Max: 10}
+
+	startScheduler(t).schedule(context.Background(), *check, metrics.NewHistory(nil), NewPublishEvents(10), NewInFlightChecks())
+
+	select {
+	case metric := <-metricSink:
+		assert.True(t, metric.PublishOK)
+		assert.WithinDuration(t, pm.PublishDate.Add(8*time.Second), metric.ObservedAt, time.Second)
+		// the first check is in (2, 4] and the second one at 8 seconds since publish
+		assert.Equal(t, metrics.Interval{LowerBound: 4, UpperBound: 8}, metric.PublishInterval)
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "check did not complete")
+	}
+}
+
+type finishedAfterCheck struct {
+	attempts int
+}
+
+func (c *finishedAfterCheck) isCurrentOperationFinished(_ context.Context, _ *PublishCheck) (operationFinished, ignoreCheck bool) {
+	c.attempts--
+	return c.attempts == 0, false
+}
@@ -1,38 +1,47 @@
 package checks
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/Financial-Times/publish-availability-monitor/config"
 	"github.com/Financial-Times/publish-availability-monitor/content"
 	"github.com/Financial-Times/publish-availability-monitor/envs"
-	"github.com/Financial-Times/publish-availability-monitor/metrics"
 )
 
+var (
+	// ErrInvalidContent is returned by the pre-checks for content which does not pass validation.
+	ErrInvalidContent = errors.New("the content is invalid")
+	// ErrPastPublishSLA is returned by the pre-checks for content published longer ago than its SLA.
+	ErrPastPublishSLA = errors.New("the content is past its publish SLA")
+)
+
+// PreCheck returns the parameters of the checks of a publish, or why it should not be checked.
 type PreCheck func(
+	ctx context.Context,
 	publishedContent content.Content,
 	tid string,
 	publishDate time.Time,
 	appConfig *config.AppConfig,
-	metricContainer *metrics.History,
 	environments *envs.Environments,
 	log *logger.UPPLogger,
-) (bool, *SchedulerParam)
+) (*SchedulerParam, error)
 
 func MainPreChecks() []PreCheck {
 	return []PreCheck{mainPreCheck}
 }
 
 func mainPreCheck(
+	ctx context.Context,
 	publishedContent content.Content,
 	tid string,
 	publishDate time.Time,
 	appConfig *config.AppConfig,
-	metricContainer *metrics.History,
 	environments *envs.Environments,
 	log *logger.UPPLogger,
-) (bool, *SchedulerParam) {
+) (*SchedulerParam, error) {
 	uuid := publishedContent.GetUUID()
 	validationEndpointKey := publishedContent.GetType()
 	var validationEndpoint string
@@ -46,27 +55,26 @@ func mainPreCheck(
 
 	logEntry := log.WithUUID(uuid).WithTransactionID(tid)
 
-	valRes := publishedContent.Validate(validationEndpoint, tid, username, password, log)
+	valRes := publishedContent.Validate(ctx, validationEndpoint, tid, username, password, log)
 	if !valRes.IsValid {
 		logEntry.Info("Message is INVALID, skipping...")
-		return false, nil
+		return nil, ErrInvalidContent
 	}
 
 	logEntry.Info("Message is VALID.")
 
-	if isMessagePastPublishSLA(publishDate, appConfig.Threshold) {
+	if isMessagePastPublishSLA(publishDate, appConfig.GetMaxThreshold(publishedContent.GetType())) {
 		logEntry.Info("Message is past publish SLA, skipping.")
-		return false, nil
+		return nil, ErrPastPublishSLA
 	}
 
-	return true, &SchedulerParam{
+	return &SchedulerParam{
 		contentToCheck:  publishedContent,
 		publishDate:     publishDate,
 		tid:             tid,
 		isMarkedDeleted: valRes.IsMarkedDeleted,
-		metricContainer: metricContainer,
 		environments:    environments,
-	}
+	}, nil
 }
 
 func isMessagePastPublishSLA(date time.Time, threshold int) bool {
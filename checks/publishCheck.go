@@ -1,9 +1,13 @@
 package checks
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"slices"
 	"time"
 
@@ -15,6 +19,9 @@ import (
 
 const DateLayout = time.RFC3339Nano
 
+// maxCheckAttempts is the number of most recent attempts a check keeps to tell why it failed
+const maxCheckAttempts = 10
+
 const (
 	NotificationsPullFeed       = "notifications"
 	NotificationsPushFeed       = "notifications-push"
@@ -33,9 +40,18 @@ type PublishCheck struct {
 	password               string
 	Threshold              int
 	CheckInterval          int
+	Polling                PollingStrategy // checks every CheckInterval if nil
+	HardLimit              int             // seconds since publish failed checks carry on until, to tell late publishes from lost ones
+	LateCheckInterval      int             // time between the checks after the SLA, CheckInterval if 0
 	ResultSink             chan metrics.PublishMetric
 	endpointSpecificChecks map[string]EndpointSpecificCheck
 	log                    *logger.UPPLogger
+
+	// why the last check could not tell whether the content is available, empty if it could
+	inconclusive metrics.InconclusiveReason
+	attempt      metrics.CheckAttempt   // the attempt in progress, described by the endpoint specific check
+	attempts     []metrics.CheckAttempt // the last maxCheckAttempts attempts, oldest first
+	resumedLate  bool                   // resumed after a restart, once its failure was reported
 }
 
 // NewPublishCheck returns a PublishCheck ready to perform a check for pm.UUID, at the pm.Endpoint.
@@ -62,15 +78,35 @@ func NewPublishCheck(
 // DoCheck performs an availability check on a piece of content at a certain
 // endpoint, applying endpoint-specific processing.
 // Returns true if the content is available at the endpoint, false otherwise.
-func (pc PublishCheck) DoCheck() (checkSuccessful, ignoreCheck bool) {
+// Endpoint specific checks can record the precise times the content was seen in pc.Metric.
+func (pc *PublishCheck) DoCheck(ctx context.Context) (checkSuccessful, ignoreCheck bool) {
 	pc.log.Infof("Running check for %s\n", pc)
+	pc.inconclusive = ""
+	pc.attempt = metrics.CheckAttempt{At: time.Now()}
+	defer pc.recordAttempt()
+
 	check := pc.endpointSpecificChecks[pc.Metric.Config.Alias]
 	if check == nil {
 		pc.log.Warnf("No check for %s", pc)
+		pc.attempt.Error = "no check for the endpoint"
 		return false, false
 	}
 
-	return check.isCurrentOperationFinished(&pc)
+	return check.isCurrentOperationFinished(ctx, pc)
+}
+
+// recordAttempt adds the attempt in progress to the attempts of the check, dropping the oldest one if there are too many.
+func (pc *PublishCheck) recordAttempt() {
+	pc.attempt.Latency = time.Since(pc.attempt.At)
+	if len(pc.attempts) == maxCheckAttempts {
+		pc.attempts = append(pc.attempts[:0], pc.attempts[1:]...)
+	}
+	pc.attempts = append(pc.attempts, pc.attempt)
+}
+
+// lastAttempts returns a copy of the last attempts of the check, oldest first.
+func (pc *PublishCheck) lastAttempts() []metrics.CheckAttempt {
+	return slices.Clone(pc.attempts)
 }
 
 func (pc PublishCheck) String() string {
@@ -85,7 +121,7 @@ func (pc PublishCheck) String() string {
 // EndpointSpecificCheck is the interface which determines the state of the operation we are currently checking.
 type EndpointSpecificCheck interface {
 	// Returns the state of the operation and whether this check should be ignored
-	isCurrentOperationFinished(pc *PublishCheck) (operationFinished, ignoreCheck bool)
+	isCurrentOperationFinished(ctx context.Context, pc *PublishCheck) (operationFinished, ignoreCheck bool)
 }
 
 // ContentCheck implements the EndpointSpecificCheck interface to check operation
@@ -99,11 +135,13 @@ func NewContentCheck(httpCaller httpcaller.Caller) ContentCheck {
 }
 
 func (c ContentCheck) isCurrentOperationFinished(
+	ctx context.Context,
 	pc *PublishCheck,
 ) (operationFinished, ignoreCheck bool) {
 	pm := pc.Metric
 	url := pm.Endpoint.String() + pm.UUID
-	resp, err := c.httpCaller.DoCall(httpcaller.Config{
+	pc.attempt.URL = url
+	resp, err := c.httpCaller.DoCall(ctx, httpcaller.Config{
 		URL:      url,
 		Username: pc.username,
 		Password: pc.password,
@@ -111,11 +149,15 @@ func (c ContentCheck) isCurrentOperationFinished(
 	})
 	if err != nil {
 		pc.log.WithError(err).Warnf("Error calling URL: [%v] for %s", url, pc)
+		pc.inconclusive = callFailureReason(err)
+		pc.attempt.Error = err.Error()
 		return false, false
 	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
+	pc.inconclusive = statusReason(resp.StatusCode)
+	pc.attempt.StatusCode = resp.StatusCode
 
 	// if the article was marked as deleted, operation is finished when the
 	// article cannot be found anymore
@@ -138,6 +180,8 @@ func (c ContentCheck) isCurrentOperationFinished(
 	if err != nil {
 		pc.log.WithError(err).Warnf("Checking %s. Cannot read response",
 			LoggingContextForCheck(pm.Config.Alias, pm.UUID, pm.Platform, pm.TID))
+		pc.inconclusive = callFailureReason(err)
+		pc.attempt.Error = fmt.Sprintf("cannot read response: %v", err)
 		return false, false
 	}
 
@@ -146,6 +190,8 @@ func (c ContentCheck) isCurrentOperationFinished(
 	if err = json.Unmarshal(data, &jsonResp); err != nil {
 		pc.log.WithError(err).Warnf("Checking %s. Cannot unmarshal JSON response",
 			LoggingContextForCheck(pm.Config.Alias, pm.UUID, pm.Platform, pm.TID))
+		pc.inconclusive = metrics.ReasonUnparseable
+		pc.attempt.Error = fmt.Sprintf("cannot unmarshal JSON response: %v", err)
 		return false, false
 	}
 
@@ -165,12 +211,14 @@ func NewContentNeo4jCheck(httpCaller httpcaller.Caller) ContentNeo4jCheck {
 //
 //nolint:unparam
 func (c ContentNeo4jCheck) isCurrentOperationFinished(
+	ctx context.Context,
 	pc *PublishCheck,
 ) (operationFinished, ignoreCheck bool) {
 	pm := pc.Metric
 	url := pm.Endpoint.String() + pm.UUID
+	pc.attempt.URL = url
 
-	resp, err := c.httpCaller.DoCall(httpcaller.Config{
+	resp, err := c.httpCaller.DoCall(ctx, httpcaller.Config{
 		URL:      url,
 		Username: pc.username,
 		Password: pc.password,
@@ -178,12 +226,16 @@ func (c ContentNeo4jCheck) isCurrentOperationFinished(
 	})
 	if err != nil {
 		pc.log.Warnf("Error calling URL: [%v] for %s", url, pc)
+		pc.inconclusive = callFailureReason(err)
+		pc.attempt.Error = err.Error()
 		return false, false
 	}
 
 	defer func() {
 		_ = resp.Body.Close()
 	}()
+	pc.inconclusive = statusReason(resp.StatusCode)
+	pc.attempt.StatusCode = resp.StatusCode
 
 	// if the article was marked as deleted, operation is finished when the
 	// article cannot be found anymore
@@ -206,6 +258,8 @@ func (c ContentNeo4jCheck) isCurrentOperationFinished(
 	if err != nil {
 		pc.log.WithError(err).Warnf("Checking %s. Cannot read response",
 			LoggingContextForCheck(pm.Config.Alias, pm.UUID, pm.Platform, pm.TID))
+		pc.inconclusive = callFailureReason(err)
+		pc.attempt.Error = fmt.Sprintf("cannot read response: %v", err)
 		return false, false
 	}
 
@@ -215,10 +269,44 @@ func (c ContentNeo4jCheck) isCurrentOperationFinished(
 	if err != nil {
 		pc.log.WithError(err).Warnf("Checking %s. Cannot unmarshal JSON response",
 			LoggingContextForCheck(pm.Config.Alias, pm.UUID, pm.Platform, pm.TID))
+		pc.inconclusive = metrics.ReasonUnparseable
+		pc.attempt.Error = fmt.Sprintf("cannot unmarshal JSON response: %v", err)
+		return false, false
+	}
+
+	uuid, ok := jsonResp["uuid"].(string)
+	if !ok {
+		pc.log.Warnf("Checking %s. The field 'uuid' is not valid: [%v]", pc, jsonResp["uuid"])
+		pc.inconclusive = metrics.ReasonUnparseable
+		pc.attempt.Error = fmt.Sprintf("invalid uuid [%v]", jsonResp["uuid"])
 		return false, false
 	}
+	return pm.UUID == uuid, false
+}
 
-	return pm.UUID == jsonResp["uuid"].(string), false
+// callFailureReason returns why a check could not tell whether the content is available
+// when calling its endpoint failed with err.
+func callFailureReason(err error) metrics.InconclusiveReason {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, httpcaller.ErrCircuitOpen):
+		return metrics.ReasonCircuitOpen
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return metrics.ReasonTimeout
+	}
+	return metrics.ReasonUnreachable
+}
+
+// statusReason returns why a check could not tell whether the content is available
+// when its endpoint responded with statusCode, or an empty reason if it could.
+func statusReason(statusCode int) metrics.InconclusiveReason {
+	switch {
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return metrics.ReasonAuth
+	case statusCode >= 500:
+		return metrics.ReasonServerError
+	}
+	return ""
 }
 
 // NotificationsCheck implements the EndpointSpecificCheck interface to build the endpoint URL and
@@ -245,6 +333,7 @@ func NewNotificationsCheck(
 }
 
 func (n NotificationsCheck) isCurrentOperationFinished(
+	ctx context.Context,
 	pc *PublishCheck,
 ) (operationFinished, ignoreCheck bool) {
 	notifications := n.checkFeed(pc.Metric.UUID, pc.Metric.Platform)
@@ -254,15 +343,21 @@ func (n NotificationsCheck) isCurrentOperationFinished(
 			"lastModified":     e.LastModified,
 		}
 		operationFinished, ignoreCheck := isSamePublishEvent(checkData, pc)
+		if operationFinished {
+			pc.Metric.NotificationReceivedAt = e.ReceivedAt
+			if lastModified, err := time.Parse(time.RFC3339Nano, e.LastModified); err == nil {
+				pc.Metric.NotificationLastModified = lastModified
+			}
+		}
 		if operationFinished || ignoreCheck {
 			return operationFinished, ignoreCheck
 		}
 	}
 
-	return false, n.shouldSkipCheck(pc)
+	return false, n.shouldSkipCheck(ctx, pc)
 }
 
-func (n NotificationsCheck) shouldSkipCheck(pc *PublishCheck) bool {
+func (n NotificationsCheck) shouldSkipCheck(ctx context.Context, pc *PublishCheck) bool {
 	pm := pc.Metric
 
 	if isSkippableNotificationInNotificationsPush(n.feedName, n.monitorList, &pm) ||
@@ -276,6 +371,7 @@ func (n NotificationsCheck) shouldSkipCheck(pc *PublishCheck) bool {
 
 	url := pm.Endpoint.String() + "/" + pm.UUID
 	resp, err := n.httpCaller.DoCall(
+		ctx,
 		httpcaller.Config{
 			URL:      url,
 			Username: pc.username,
@@ -328,6 +424,8 @@ func isSamePublishEvent(
 	pc *PublishCheck,
 ) (operationFinished, ignoreCheck bool) {
 	pm := pc.Metric
+	pc.attempt.PublishReference, _ = jsonContent["publishReference"].(string)
+	pc.attempt.LastModified, _ = jsonContent["lastModified"].(string)
 	if jsonContent["publishReference"] == pm.TID {
 		pc.log.Infof("Checking %s. Matched publish reference.", pc)
 		return true, false
@@ -1,6 +1,7 @@
 package checks
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -73,10 +74,45 @@ func TestFeedContainsMatchingNotification(t *testing.T) {
 		nil,
 		log,
 	)
-	finished, _ := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, _ := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.True(t, finished, "Operation should be considered finished")
 }
 
+func TestFeedMatchingNotificationRecordsItsTimes(t *testing.T) {
+	testUUID := uuid.NewString()
+	testTID := "tid_0123wxyz"
+	receivedAt := time.Now()
+
+	n := feeds.Notification{ID: testUUID, PublishReference: testTID, LastModified: "2016-10-28T14:00:00.123Z", ReceivedAt: receivedAt}
+	subscribedFeeds := map[string][]feeds.Feed{
+		testEnv: {mockFeed(feedName, testUUID, []*feeds.Notification{&n})},
+	}
+
+	notificationsCheck := &NotificationsCheck{
+		mockHTTPCaller(t, "", nil),
+		subscribedFeeds,
+		[]string{FTPinkPublication},
+		feedName,
+	}
+	log := logger.NewUPPLogger("test", "PANIC")
+
+	pc := NewPublishCheck(
+		newPublishMetricBuilder().withUUID(testUUID).withPlatform(testEnv).withTID(testTID).build(),
+		"",
+		"",
+		0,
+		0,
+		nil,
+		map[string]EndpointSpecificCheck{"": notificationsCheck},
+		log,
+	)
+	finished, _ := pc.DoCheck(context.Background())
+
+	assert.True(t, finished, "Operation should be considered finished")
+	assert.Equal(t, receivedAt, pc.Metric.NotificationReceivedAt)
+	assert.Equal(t, time.Date(2016, 10, 28, 14, 0, 0, 123000000, time.UTC), pc.Metric.NotificationLastModified)
+}
+
 func TestFeedMissingNotification(t *testing.T) {
 	testUUID := uuid.NewString()
 	testTID := "tid_0123wxyz"
@@ -103,7 +139,7 @@ func TestFeedMissingNotification(t *testing.T) {
 		nil,
 		log,
 	)
-	finished, _ := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, _ := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.False(t, finished, "Operation should not be considered finished")
 }
 
@@ -147,7 +183,7 @@ func TestFeedContainsEarlierNotification(t *testing.T) {
 		nil,
 		log,
 	)
-	finished, ignore := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, ignore := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.False(t, finished, "Operation should not be considered finished")
 	assert.False(t, ignore, "Operation should not be skipped")
 }
@@ -191,7 +227,7 @@ func TestFeedContainsLaterNotification(t *testing.T) {
 		nil,
 		log,
 	)
-	_, ignore := notificationsCheck.isCurrentOperationFinished(pc)
+	_, ignore := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.True(t, ignore, "Operation should be skipped")
 }
 
@@ -235,7 +271,7 @@ func TestFeedContainsUnparseableNotification(t *testing.T) {
 		nil,
 		log,
 	)
-	finished, ignore := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, ignore := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.False(t, finished, "Operation should not be considered finished")
 	assert.False(t, ignore, "Operation should not be skipped")
 }
@@ -273,7 +309,7 @@ func TestMissingFeed(t *testing.T) {
 		nil,
 		log,
 	)
-	finished, ignore := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, ignore := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.False(t, finished, "Operation should not be considered finished")
 	assert.False(t, ignore, "Operation should not be ignored")
 }
@@ -311,7 +347,7 @@ func TestMissingEnvironment(t *testing.T) {
 		nil,
 		log,
 	)
-	finished, ignore := notificationsCheck.isCurrentOperationFinished(pc)
+	finished, ignore := notificationsCheck.isCurrentOperationFinished(context.Background(), pc)
 	assert.False(t, finished, "Operation should not be considered finished")
 	assert.False(t, ignore, "Operation should not be ignored")
 }
@@ -323,7 +359,7 @@ func TestShouldSkipCheck_ContentIsNotMarkedAsDeleted_CheckNotSkipped(t *testing.
 
 	pc := NewPublishCheck(pm, "", "", 0, 0, nil, nil, log)
 
-	if notificationsCheck.shouldSkipCheck(pc) {
+	if notificationsCheck.shouldSkipCheck(context.Background(), pc) {
 		t.Errorf("Expected failure")
 	}
 }
@@ -345,7 +381,7 @@ func TestShouldSkipCheck_ContentIsMarkedAsDeletedPreviousNotificationsExist_Chec
 		httpCaller: mockHTTPCaller(t, "", response),
 		feedName:   feedName,
 	}
-	if notificationsCheck.shouldSkipCheck(pc) {
+	if notificationsCheck.shouldSkipCheck(context.Background(), pc) {
 		t.Errorf("Expected failure")
 	}
 }
@@ -364,7 +400,7 @@ func TestShouldSkipCheck_ContentIsMarkedAsDeletedPreviousNotificationsDoesNotExi
 		httpCaller: mockHTTPCaller(t, "", response),
 		feedName:   feedName,
 	}
-	if !notificationsCheck.shouldSkipCheck(pc) {
+	if !notificationsCheck.shouldSkipCheck(context.Background(), pc) {
 		t.Errorf("Expected success")
 	}
 }
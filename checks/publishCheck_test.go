@@ -2,6 +2,8 @@ package checks
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +15,7 @@ import (
 	"github.com/Financial-Times/publish-availability-monitor/httpcaller"
 	"github.com/Financial-Times/publish-availability-monitor/metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIsCurrentOperationFinished_ContentCheck_InvalidContent(t *testing.T) {
@@ -26,7 +29,7 @@ func TestIsCurrentOperationFinished_ContentCheck_InvalidContent(t *testing.T) {
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -50,7 +53,7 @@ func TestIsCurrentOperationFinished_ContentCheck_Finished(t *testing.T) {
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -76,7 +79,7 @@ func TestIsCurrentOperationFinished_ContentCheck_WithAuthentication(t *testing.T
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		username,
 		password,
@@ -100,7 +103,7 @@ func TestIsCurrentOperationFinished_ContentCheck_NotFinished(t *testing.T) {
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -124,7 +127,7 @@ func TestIsCurrentOperationFinished_ContentCheck_MarkedDeleted_Finished(t *testi
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withMarkedDeleted(true).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -148,7 +151,7 @@ func TestIsCurrentOperationFinished_ContentCheck_MarkedDeleted_NotFinished(t *te
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withMarkedDeleted(true).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -177,7 +180,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsAfterCurrentP
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	_, ignoreCheck := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	_, ignoreCheck := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -208,7 +211,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsBeforeCurrent
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	_, ignoreCheck := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	_, ignoreCheck := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -237,7 +240,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsBeforeCurrent
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	_, ignoreCheck := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	_, ignoreCheck := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -266,7 +269,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsBeforeCurrent
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -300,7 +303,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateEqualsCurrentPu
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -333,7 +336,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsNullCurrentTI
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -366,7 +369,7 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsEmptyStringCu
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withTID(currentTid).withPublishDate(publishDate).build()
-	finished, _ := contentCheck.isCurrentOperationFinished(NewPublishCheck(
+	finished, _ := contentCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -379,6 +382,91 @@ func TestIsCurrentOperationFinished_ContentCheck_LastModifiedDateIsEmptyStringCu
 	assert.True(t, finished, "operation should have finished successfully")
 }
 
+func TestIsCurrentOperationFinished_ContentCheck_InconclusiveReason(t *testing.T) {
+	currentTid := "tid_1234"
+	tests := map[string]struct {
+		HTTPCaller     httpcaller.Caller
+		MarkedDeleted  bool
+		ExpectedReason metrics.InconclusiveReason
+	}{
+		"not found": {
+			HTTPCaller: mockHTTPCaller(t, "", buildResponse(404, "")),
+		},
+		"server error": {
+			HTTPCaller:     mockHTTPCaller(t, "", buildResponse(503, "")),
+			ExpectedReason: metrics.ReasonServerError,
+		},
+		"server error when marked deleted": {
+			HTTPCaller:     mockHTTPCaller(t, "", buildResponse(500, "")),
+			MarkedDeleted:  true,
+			ExpectedReason: metrics.ReasonServerError,
+		},
+		"unauthorised": {
+			HTTPCaller:     mockHTTPCaller(t, "", buildResponse(401, "")),
+			ExpectedReason: metrics.ReasonAuth,
+		},
+		"forbidden": {
+			HTTPCaller:     mockHTTPCaller(t, "", buildResponse(403, "")),
+			ExpectedReason: metrics.ReasonAuth,
+		},
+		"invalid JSON": {
+			HTTPCaller:     mockHTTPCaller(t, "", buildResponse(200, `{ "uuid" : "1234-1234"`)),
+			ExpectedReason: metrics.ReasonUnparseable,
+		},
+		"timeout": {
+			HTTPCaller:     failingHTTPCaller{&url.Error{Op: "Get", URL: "http://localhost/content", Err: context.DeadlineExceeded}},
+			ExpectedReason: metrics.ReasonTimeout,
+		},
+		"connection refused": {
+			HTTPCaller:     failingHTTPCaller{&url.Error{Op: "Get", URL: "http://localhost/content", Err: errors.New("connection refused")}},
+			ExpectedReason: metrics.ReasonUnreachable,
+		},
+		"circuit open": {
+			HTTPCaller:     failingHTTPCaller{fmt.Errorf("%w for localhost", httpcaller.ErrCircuitOpen)},
+			ExpectedReason: metrics.ReasonCircuitOpen,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pm := newPublishMetricBuilder().withTID(currentTid).withMarkedDeleted(test.MarkedDeleted).build()
+			pc := NewPublishCheck(pm, "", "", 0, 0, nil, map[string]EndpointSpecificCheck{pm.Config.Alias: ContentCheck{test.HTTPCaller}}, logger.NewUPPLogger("test", "PANIC"))
+
+			finished, _ := pc.DoCheck(context.Background())
+			assert.False(t, finished)
+			assert.Equal(t, test.ExpectedReason, pc.inconclusive)
+		})
+	}
+}
+
+func TestPublishCheckRecordsLastAttempts(t *testing.T) {
+	currentTid := "tid_1234"
+	var responses []*http.Response
+	for i := 0; i < maxCheckAttempts+1; i++ {
+		if i%2 == 0 {
+			responses = append(responses, buildResponse(404, ""))
+		} else {
+			responses = append(responses, buildResponse(200, `{ "uuid" : "1234-1234", "publishReference" : "tid_previous"}`))
+		}
+	}
+	pm := newPublishMetricBuilder().withUUID("1234-1234").withTID(currentTid).withEndpoint("http://localhost:8080/content/").build()
+	pc := NewPublishCheck(pm, "", "", 0, 0, nil, map[string]EndpointSpecificCheck{pm.Config.Alias: ContentCheck{mockHTTPCaller(t, "", responses...)}}, logger.NewUPPLogger("test", "PANIC"))
+
+	for i := 0; i < maxCheckAttempts+1; i++ {
+		finished, _ := pc.DoCheck(context.Background())
+		assert.False(t, finished)
+	}
+
+	attempts := pc.lastAttempts()
+	require.Len(t, attempts, maxCheckAttempts, "only the last attempts should be kept")
+	assert.Equal(t, 200, attempts[0].StatusCode, "the oldest attempt should have been dropped")
+	assert.Equal(t, "http://localhost:8080/content/1234-1234", attempts[0].URL)
+	assert.Equal(t, "tid_previous", attempts[0].PublishReference)
+	assert.Equal(t, 404, attempts[1].StatusCode)
+	assert.Empty(t, attempts[1].PublishReference)
+	assert.False(t, attempts[0].At.IsZero())
+}
+
 func TestIsCurrentOperationFinished_PushNotifications_EditorialDesk_Ignored(t *testing.T) {
 	testResponse := `[]`
 
@@ -393,7 +481,7 @@ func TestIsCurrentOperationFinished_PushNotifications_EditorialDesk_Ignored(t *t
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	pm := newPublishMetricBuilder().withEditorialDesk(CentralBankingEditorialDesk).build()
-	_, ignoreCheck := notificationCheck.isCurrentOperationFinished(NewPublishCheck(
+	_, ignoreCheck := notificationCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -421,7 +509,7 @@ func TestIsCurrentOperationFinished_PushNotifications_Publication_Ignored(t *tes
 
 	pm := newPublishMetricBuilder().withPublication([]string{SustainableViewsPublication}).
 		build()
-	_, ignoreCheck := notificationCheck.isCurrentOperationFinished(NewPublishCheck(
+	_, ignoreCheck := notificationCheck.isCurrentOperationFinished(context.Background(), NewPublishCheck(
 		pm,
 		"",
 		"",
@@ -534,7 +622,7 @@ type testHTTPCaller struct {
 }
 
 // returns the mock responses of testHTTPCaller in order
-func (t *testHTTPCaller) DoCall(config httpcaller.Config) (*http.Response, error) {
+func (t *testHTTPCaller) DoCall(_ context.Context, config httpcaller.Config) (*http.Response, error) {
 	if t.authUser != config.Username || t.authPass != config.Password {
 		return buildResponse(401, `{message: "Not authenticated"}`), nil
 	}
@@ -548,6 +636,15 @@ func (t *testHTTPCaller) DoCall(config httpcaller.Config) (*http.Response, error
 	return response, nil
 }
 
+// failingHTTPCaller fails every call with err
+type failingHTTPCaller struct {
+	err error
+}
+
+func (c failingHTTPCaller) DoCall(_ context.Context, _ httpcaller.Config) (*http.Response, error) {
+	return nil, c.err
+}
+
 // builds testHTTPCaller with the given mocked responses in the provided order
 func mockHTTPCaller(t *testing.T, tid string, responses ...*http.Response) httpcaller.Caller {
 	return &testHTTPCaller{t: t, tid: tid, mockResponses: responses}
@@ -0,0 +1,176 @@
+package checks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+)
+
+// CheckStatus is the state of a single scheduled check of a publish.
+type CheckStatus string
+
+const (
+	CheckPending   CheckStatus = "pending"
+	CheckSucceeded CheckStatus = "succeeded"
+	CheckFailed    CheckStatus = "failed"
+	CheckIgnored   CheckStatus = "ignored"
+	// the monitor could not check the endpoint, the reason of the check tells why
+	CheckInconclusive CheckStatus = "inconclusive"
+	// the monitor stopped before the check could finish
+	CheckInterrupted CheckStatus = "interrupted"
+)
+
+// Status of a publish event as a whole.
+const (
+	PublishInProgress = "inProgress"
+	PublishSucceeded  = "succeeded"
+	PublishFailed     = "failed"
+	// no check failed, but some could not tell whether the publish succeeded, or were interrupted or cancelled
+	PublishInconclusive = "inconclusive"
+	// every check was ignored, as the content was published again since
+	PublishIgnored = "ignored"
+)
+
+// ScheduledCheck tracks the check of one endpoint in one environment for a publish.
+type ScheduledCheck struct {
+	EndpointAlias string                     `json:"endpoint"`
+	Environment   string                     `json:"environment"`
+	Status        CheckStatus                `json:"status"`
+	Reason        metrics.InconclusiveReason `json:"inconclusiveReason,omitempty"`
+	Interval      *metrics.Interval          `json:"interval,omitempty"`
+	UpdatedAt     time.Time                  `json:"updatedAt"`
+}
+
+// PublishEvent groups all the checks scheduled for a single publish.
+type PublishEvent struct {
+	TID         string           `json:"transactionId"`
+	UUID        string           `json:"uuid"`
+	ContentType string           `json:"contentType"`
+	PublishDate time.Time        `json:"publishDate"`
+	Status      string           `json:"status"`
+	Checks      []ScheduledCheck `json:"checks"`
+}
+
+// PublishEvents keeps the most recent publish events, keyed by transaction ID.
+// A nil *PublishEvents is valid and tracks nothing.
+type PublishEvents struct {
+	mu        sync.RWMutex
+	events    map[string]*PublishEvent
+	tids      []string // oldest first, used to evict events once maxEvents is reached
+	maxEvents int
+}
+
+func NewPublishEvents(maxEvents int) *PublishEvents {
+	return &PublishEvents{
+		events:    make(map[string]*PublishEvent),
+		maxEvents: maxEvents,
+	}
+}
+
+// scheduled records a pending check for the publish pm belongs to.
+func (e *PublishEvents) scheduled(pm metrics.PublishMetric, contentType string) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	event, found := e.events[pm.TID]
+	if !found {
+		event = &PublishEvent{
+			TID:         pm.TID,
+			UUID:        pm.UUID,
+			ContentType: contentType,
+			PublishDate: pm.PublishDate,
+		}
+		e.events[pm.TID] = event
+		e.tids = append(e.tids, pm.TID)
+		e.evict()
+	}
+
+	event.Checks = append(event.Checks, ScheduledCheck{
+		EndpointAlias: pm.Config.Alias,
+		Environment:   pm.Platform,
+		Status:        CheckPending,
+		UpdatedAt:     time.Now(),
+	})
+}
+
+// completed sets the final status of the check pm was produced by.
+// A publish checked again has a check per run for the same endpoint and environment,
+// and a run completes the oldest one still pending.
+func (e *PublishEvents) completed(pm metrics.PublishMetric, status CheckStatus) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	event, found := e.events[pm.TID]
+	if !found {
+		return
+	}
+
+	for i := range event.Checks {
+		c := &event.Checks[i]
+		if c.EndpointAlias == pm.Config.Alias && c.Environment == pm.Platform && c.Status == CheckPending {
+			interval := pm.PublishInterval
+			c.Status = status
+			c.Reason = pm.Reason
+			c.Interval = &interval
+			c.UpdatedAt = time.Now()
+			return
+		}
+	}
+}
+
+func (e *PublishEvents) evict() {
+	for len(e.tids) > e.maxEvents {
+		delete(e.events, e.tids[0])
+		e.tids = e.tids[1:]
+	}
+}
+
+// Get returns a snapshot of the publish event with the given transaction ID.
+func (e *PublishEvents) Get(tid string) (PublishEvent, bool) {
+	if e == nil {
+		return PublishEvent{}, false
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	event, found := e.events[tid]
+	if !found {
+		return PublishEvent{}, false
+	}
+
+	snapshot := *event
+	snapshot.Checks = append([]ScheduledCheck(nil), event.Checks...)
+	snapshot.Status = publishStatus(snapshot.Checks)
+	return snapshot, true
+}
+
+func publishStatus(checks []ScheduledCheck) string {
+	status := PublishIgnored
+	for _, c := range checks {
+		switch c.Status {
+		case CheckPending:
+			return PublishInProgress
+		case CheckFailed:
+			status = PublishFailed
+		case CheckInconclusive, CheckInterrupted, CheckCancelled:
+			if status != PublishFailed {
+				status = PublishInconclusive
+			}
+		case CheckSucceeded:
+			if status == PublishIgnored {
+				status = PublishSucceeded
+			}
+		}
+	}
+	return status
+}
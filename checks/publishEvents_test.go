@@ -0,0 +1,140 @@
+package checks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishEventsTracksChecksOfAPublish(t *testing.T) {
+	events := NewPublishEvents(10)
+	publishDate := time.Now()
+
+	contentEU := metrics.PublishMetric{TID: "tid_1", UUID: "uuid-1", PublishDate: publishDate, Platform: "eu", Config: config.MetricConfig{Alias: "content"}}
+	contentUS := metrics.PublishMetric{TID: "tid_1", UUID: "uuid-1", PublishDate: publishDate, Platform: "us", Config: config.MetricConfig{Alias: "content"}}
+	notificationsEU := metrics.PublishMetric{TID: "tid_1", UUID: "uuid-1", PublishDate: publishDate, Platform: "eu", Config: config.MetricConfig{Alias: "notifications"}}
+
+	events.scheduled(contentEU, "application/vnd.ft-upp-article-internal+json")
+	events.scheduled(contentUS, "application/vnd.ft-upp-article-internal+json")
+	events.scheduled(notificationsEU, "application/vnd.ft-upp-article-internal+json")
+
+	event, found := events.Get("tid_1")
+	require.True(t, found)
+	assert.Equal(t, PublishInProgress, event.Status)
+	assert.Equal(t, "uuid-1", event.UUID)
+	assert.Equal(t, "application/vnd.ft-upp-article-internal+json", event.ContentType)
+	require.Len(t, event.Checks, 3)
+
+	contentEU.PublishInterval = metrics.Interval{LowerBound: 3, UpperBound: 6}
+	events.completed(contentEU, CheckSucceeded)
+	events.completed(notificationsEU, CheckIgnored)
+
+	event, _ = events.Get("tid_1")
+	assert.Equal(t, PublishInProgress, event.Status)
+	assert.Equal(t, CheckSucceeded, event.Checks[0].Status)
+	assert.Equal(t, &metrics.Interval{LowerBound: 3, UpperBound: 6}, event.Checks[0].Interval)
+	assert.Equal(t, CheckPending, event.Checks[1].Status)
+	assert.Equal(t, CheckIgnored, event.Checks[2].Status)
+
+	contentUS.Reason = metrics.ReasonServerError
+	events.completed(contentUS, CheckInconclusive)
+
+	event, _ = events.Get("tid_1")
+	assert.Equal(t, PublishInconclusive, event.Status)
+	assert.Equal(t, metrics.ReasonServerError, event.Checks[1].Reason)
+}
+
+func TestPublishEventsTracksPublishesCheckedAgain(t *testing.T) {
+	events := NewPublishEvents(10)
+	pm := metrics.PublishMetric{TID: "tid_1", UUID: "uuid-1", PublishDate: time.Now(), Platform: "eu", Config: config.MetricConfig{Alias: "content"}}
+
+	events.scheduled(pm, "")
+	events.completed(pm, CheckFailed)
+	events.scheduled(pm, "")
+
+	event, _ := events.Get("tid_1")
+	assert.Equal(t, PublishInProgress, event.Status)
+	require.Len(t, event.Checks, 2)
+	assert.Equal(t, CheckFailed, event.Checks[0].Status)
+	assert.Equal(t, CheckPending, event.Checks[1].Status)
+
+	events.completed(pm, CheckSucceeded)
+
+	event, _ = events.Get("tid_1")
+	assert.Equal(t, PublishFailed, event.Status, "the publish should not be in progress once the check run again completed")
+	assert.Equal(t, CheckFailed, event.Checks[0].Status)
+	assert.Equal(t, CheckSucceeded, event.Checks[1].Status)
+}
+
+func TestPublishStatus(t *testing.T) {
+	tests := map[string]struct {
+		Statuses       []CheckStatus
+		ExpectedStatus string
+	}{
+		"pending check": {
+			Statuses:       []CheckStatus{CheckFailed, CheckPending},
+			ExpectedStatus: PublishInProgress,
+		},
+		"successful checks": {
+			Statuses:       []CheckStatus{CheckSucceeded, CheckIgnored},
+			ExpectedStatus: PublishSucceeded,
+		},
+		"failed check": {
+			Statuses:       []CheckStatus{CheckInconclusive, CheckFailed, CheckSucceeded},
+			ExpectedStatus: PublishFailed,
+		},
+		"inconclusive check": {
+			Statuses:       []CheckStatus{CheckSucceeded, CheckInconclusive},
+			ExpectedStatus: PublishInconclusive,
+		},
+		"interrupted check": {
+			Statuses:       []CheckStatus{CheckInterrupted, CheckSucceeded},
+			ExpectedStatus: PublishInconclusive,
+		},
+		"cancelled check": {
+			Statuses:       []CheckStatus{CheckSucceeded, CheckCancelled},
+			ExpectedStatus: PublishInconclusive,
+		},
+		"ignored checks": {
+			Statuses:       []CheckStatus{CheckIgnored, CheckIgnored},
+			ExpectedStatus: PublishIgnored,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var checks []ScheduledCheck
+			for _, status := range test.Statuses {
+				checks = append(checks, ScheduledCheck{Status: status})
+			}
+			assert.Equal(t, test.ExpectedStatus, publishStatus(checks))
+		})
+	}
+}
+
+func TestPublishEventsEvictsOldestPublishes(t *testing.T) {
+	events := NewPublishEvents(2)
+
+	for _, tid := range []string{"tid_1", "tid_2", "tid_3"} {
+		events.scheduled(metrics.PublishMetric{TID: tid}, "")
+	}
+
+	_, found := events.Get("tid_1")
+	assert.False(t, found)
+	_, found = events.Get("tid_3")
+	assert.True(t, found)
+}
+
+func TestNilPublishEventsTracksNothing(t *testing.T) {
+	var events *PublishEvents
+
+	events.scheduled(metrics.PublishMetric{TID: "tid_1"}, "")
+	events.completed(metrics.PublishMetric{TID: "tid_1"}, CheckSucceeded)
+
+	_, found := events.Get("tid_1")
+	assert.False(t, found)
+}
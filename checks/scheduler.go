@@ -1,6 +1,7 @@
 package checks
 
 import (
+	"context"
 	"net/url"
 	"regexp"
 	"time"
@@ -10,8 +11,14 @@ import (
 	"github.com/Financial-Times/publish-availability-monitor/content"
 	"github.com/Financial-Times/publish-availability-monitor/envs"
 	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/Financial-Times/publish-availability-monitor/checks")
+
 var AbsoluteURLRegex = regexp.MustCompile("(?i)https?://.*")
 
 type SchedulerParam struct {
@@ -19,25 +26,68 @@ type SchedulerParam struct {
 	publishDate     time.Time
 	tid             string
 	isMarkedDeleted bool
-	metricContainer *metrics.History
 	environments    *envs.Environments
+	filter          CheckFilter
+}
+
+// Restrict limits the checks scheduled with p to the ones filter allows.
+func (p *SchedulerParam) Restrict(filter CheckFilter) {
+	p.filter = filter
+}
+
+// CheckFilter restricts the checks scheduled for a publish to some endpoints and environments.
+// An empty list does not restrict.
+type CheckFilter struct {
+	Endpoints    []string `json:"endpoints,omitempty"` // endpoint aliases
+	Environments []string `json:"environments,omitempty"`
+}
+
+func (f CheckFilter) allowsEndpoint(alias string) bool {
+	return len(f.Endpoints) == 0 || strSliceContains(f.Endpoints, alias)
 }
 
+func (f CheckFilter) allowsEnvironment(name string) bool {
+	return len(f.Environments) == 0 || strSliceContains(f.Environments, name)
+}
+
+// SchedulerDeps are what the publish checks report their outcomes to, and the scheduler running them.
+type SchedulerDeps struct {
+	MetricSink    chan metrics.PublishMetric
+	History       *metrics.History
+	PublishEvents *PublishEvents
+	InFlight      *InFlightChecks
+	Scheduler     *CheckScheduler
+}
+
+// ScheduleChecks schedules the checks of the published content on every configured endpoint and environment,
+// and returns how many were scheduled. Once the scheduler is stopped, the metric sink may be closed,
+// so nothing is scheduled nor reported.
+//
 //nolint:gocognit
 func ScheduleChecks(
+	ctx context.Context,
 	p *SchedulerParam,
 	endpointSpecificChecks map[string]EndpointSpecificCheck,
 	appConfig *config.AppConfig,
-	metricSink chan metrics.PublishMetric,
+	deps SchedulerDeps,
 	e2eTestUUIDs []string,
 	log *logger.UPPLogger,
-) {
+) int {
+	if deps.Scheduler.stopped() {
+		log.WithTransactionID(p.tid).Warn("Cannot check publish, the monitor is shutting down")
+		return 0
+	}
+
 	isE2ETest := config.IsE2ETestTransactionID(p.tid, e2eTestUUIDs)
 
+	scheduled := 0
 	for _, metric := range appConfig.MetricConf {
 		if !strSliceContains(metric.ContentTypes, p.contentToCheck.GetType()) && !isE2ETest {
 			continue
 		}
+		if !p.filter.allowsEndpoint(metric.Alias) {
+			continue
+		}
 
 		var capability *config.Capability
 		if isE2ETest {
@@ -47,8 +97,12 @@ func ScheduleChecks(
 			}
 		}
 
+		threshold := appConfig.GetThreshold(metric, p.contentToCheck.GetType())
 		if p.environments.Len() > 0 {
 			for _, name := range p.environments.Names() {
+				if !p.filter.allowsEnvironment(name) {
+					continue
+				}
 				env := p.environments.Environment(name)
 				var endpointURL *url.URL
 				var err error
@@ -68,6 +122,7 @@ func ScheduleChecks(
 					UUID:            p.contentToCheck.GetUUID(),
 					EditorialDesk:   p.contentToCheck.GetEditorialDesk(),
 					Publication:     p.contentToCheck.GetPublication(),
+					ContentType:     p.contentToCheck.GetType(),
 					PublishOK:       false,
 					PublishDate:     p.publishDate,
 					Platform:        name,
@@ -79,18 +134,31 @@ func ScheduleChecks(
 					Capability:      capability,
 				}
 
-				checkInterval := appConfig.Threshold / metric.Granularity
+				checkInterval := metric.GetCheckInterval(threshold)
 				publishCheck := NewPublishCheck(
 					publishMetric,
 					env.Username,
 					env.Password,
-					appConfig.Threshold,
+					threshold,
 					checkInterval,
-					metricSink,
+					deps.MetricSink,
 					endpointSpecificChecks,
 					log,
 				)
-				go scheduleCheck(*publishCheck, p.metricContainer)
+				publishCheck.Polling = newPollingStrategy(metric, checkInterval, deps.History)
+				publishCheck.HardLimit = appConfig.LateTrackingConf.HardLimitSeconds
+				publishCheck.LateCheckInterval = appConfig.LateTrackingConf.IntervalSeconds
+				deps.PublishEvents.scheduled(publishMetric, p.contentToCheck.GetType())
+				if !deps.Scheduler.schedule(ctx, *publishCheck, deps.History, deps.PublishEvents, deps.InFlight) {
+					// the monitor is shutting down
+					log.Infof("Interrupted check for %s before it started", publishCheck)
+					publishMetric.Outcome = metrics.OutcomeInterrupted
+					deps.PublishEvents.completed(publishMetric, CheckInterrupted)
+					deps.MetricSink <- publishMetric
+					deps.History.Update(publishMetric)
+					continue
+				}
+				scheduled++
 			}
 		} else {
 			// generate a generic failure metric so that the absence of monitoring is logged
@@ -98,7 +166,9 @@ func ScheduleChecks(
 				UUID:            p.contentToCheck.GetUUID(),
 				EditorialDesk:   p.contentToCheck.GetEditorialDesk(),
 				Publication:     p.contentToCheck.GetPublication(),
+				ContentType:     p.contentToCheck.GetType(),
 				PublishOK:       false,
+				Outcome:         metrics.OutcomeFailure,
 				PublishDate:     p.publishDate,
 				Platform:        "none",
 				PublishInterval: metrics.Interval{},
@@ -108,16 +178,135 @@ func ScheduleChecks(
 				IsMarkedDeleted: p.isMarkedDeleted,
 				Capability:      capability,
 			}
-			metricSink <- publishMetric
-			p.metricContainer.Update(publishMetric)
+			deps.PublishEvents.scheduled(publishMetric, p.contentToCheck.GetType())
+			deps.PublishEvents.completed(publishMetric, CheckFailed)
+			deps.MetricSink <- publishMetric
+			deps.History.Update(publishMetric)
 		}
 	}
+	return scheduled
 }
 
-func scheduleCheck(check PublishCheck, metricContainer *metrics.History) {
+// ResumeChecks schedules again the checks checkpointed before a restart. As for the checks of a new publish,
+// the time left until their SLA, or the hard limit of late tracking, is computed from the publish date,
+// so the checks missed while the monitor was down are skipped.
+// The checks of an endpoint or an environment no longer configured are dropped.
+// It returns how many checks were resumed.
+func ResumeChecks(
+	ctx context.Context,
+	states []CheckState,
+	endpointSpecificChecks map[string]EndpointSpecificCheck,
+	appConfig *config.AppConfig,
+	environments *envs.Environments,
+	deps SchedulerDeps,
+	log *logger.UPPLogger,
+) int {
+	resumed := 0
+	for _, state := range states {
+		publishMetric := state.Metric
+		logContext := LoggingContextForCheck(publishMetric.Config.Alias, publishMetric.UUID, publishMetric.Platform, publishMetric.TID)
+
+		// the checkpointed config has no API key
+		metric, found := metricConfig(appConfig, publishMetric.Config.Alias)
+		if !found {
+			log.Warnf("Cannot resume check for %s, its endpoint is no longer configured", logContext)
+			continue
+		}
+		env := environments.Environment(publishMetric.Platform)
+		if env.Name == "" {
+			log.Warnf("Cannot resume check for %s, its environment is no longer configured", logContext)
+			continue
+		}
+		publishMetric.Config = metric
+		attempts := publishMetric.Attempts
+		publishMetric.Attempts = nil
+
+		publishCheck := NewPublishCheck(
+			publishMetric,
+			env.Username,
+			env.Password,
+			state.Threshold,
+			state.CheckInterval,
+			deps.MetricSink,
+			endpointSpecificChecks,
+			log,
+		)
+		publishCheck.Polling = newPollingStrategy(metric, state.CheckInterval, deps.History)
+		publishCheck.HardLimit = state.HardLimit
+		publishCheck.LateCheckInterval = state.LateCheckInterval
+		publishCheck.attempts = attempts
+		publishCheck.resumedLate = state.LateTracking
+
+		log.Infof("Resuming check for %s", logContext)
+		if !state.LateTracking {
+			deps.PublishEvents.scheduled(publishMetric, publishMetric.ContentType)
+		}
+		if !deps.Scheduler.schedule(ctx, *publishCheck, deps.History, deps.PublishEvents, deps.InFlight) {
+			deps.PublishEvents.completed(publishMetric, CheckInterrupted)
+			continue
+		}
+		resumed++
+	}
+	return resumed
+}
+
+func metricConfig(appConfig *config.AppConfig, alias string) (config.MetricConfig, bool) {
+	for _, metric := range appConfig.MetricConf {
+		if metric.Alias == alias {
+			return metric, true
+		}
+	}
+	return config.MetricConfig{}, false
+}
+
+// checkRun is a publish check scheduled on a CheckScheduler, which performs one attempt at a time.
+type checkRun struct {
+	ctx             context.Context
+	span            trace.Span
+	check           PublishCheck
+	metricContainer *metrics.History
+	publishEvents   *PublishEvents
+	inFlight        *InFlightChecks
+	checkID         string
+	cancelled       <-chan struct{}
+	release         func()                 // frees the context of the check once it is over
+	halted          <-chan struct{}        // closed when the checks left are interrupted
+	suspended       <-chan struct{}        // closed when the checks left were checkpointed, to be resumed after the restart
+	interrupted     bool                   // the check could not finish before the monitor stopped
+	next            func(previous int) int // when the check following the one at previous runs, up to the SLA
+	lower, upper    int                    // the interval of the current attempt, in seconds since publish
+	attempts        int
+	lateTracking    bool // the publish missed its SLA and is checked up to the hard limit
+	due             time.Time
+	index           int // in the queue of the scheduler, -1 if not queued
+}
+
+func newCheckRun(
+	ctx context.Context,
+	interrupt context.Context,
+	check PublishCheck,
+	metricContainer *metrics.History,
+	publishEvents *PublishEvents,
+	inFlight *InFlightChecks,
+) *checkRun {
+	// the span outlives the one of the message handler, which returns as soon as the checks are scheduled
+	ctx, span := tracer.Start(ctx, "scheduleCheck")
+	span.SetAttributes(
+		attribute.String("endpoint", check.Metric.Config.Alias),
+		attribute.String("environment", check.Metric.Platform),
+		attribute.String("uuid", check.Metric.UUID),
+		attribute.String("transaction_id", check.Metric.TID),
+	)
+
+	// the calls of the check are cancelled once interrupt is done
+	ctx, cancel := context.WithCancel(ctx)
+	stopInterrupt := context.AfterFunc(interrupt, cancel)
+
 	// the date the SLA expires for this publish event
 	publishSLA := check.Metric.PublishDate.Add(time.Duration(check.Threshold) * time.Second)
 
+	checkID, cancelled := inFlight.add(check.Metric, publishSLA)
+
 	// compute the actual seconds left until the SLA to compensate for the
 	// time passed between publish and the message reaching this point
 	secondsUntilSLA := time.Until(publishSLA).Seconds()
@@ -128,13 +317,6 @@ func scheduleCheck(check PublishCheck, metricContainer *metrics.History) {
 			check.Metric.TID),
 		int(secondsUntilSLA))
 
-	// used to signal the ticker to stop after the threshold duration is reached
-	quitChan := make(chan bool)
-	go func() {
-		<-time.After(time.Duration(secondsUntilSLA) * time.Second)
-		close(quitChan)
-	}()
-
 	secondsSincePublish := time.Since(check.Metric.PublishDate).Seconds()
 	check.log.Infof("Checking %s. [%v] seconds elapsed since publish.",
 		LoggingContextForCheck(check.Metric.Config.Alias,
@@ -143,59 +325,342 @@ func scheduleCheck(check PublishCheck, metricContainer *metrics.History) {
 			check.Metric.TID),
 		int(secondsSincePublish))
 
-	elapsedIntervals := secondsSincePublish / float64(check.CheckInterval)
+	polling := check.Polling
+	if polling == nil {
+		polling = FixedPolling{Interval: check.CheckInterval}
+	}
+	// the checks are scheduled in seconds since publish, up to the SLA;
+	// the first one runs straight away, in the interval the time elapsed since publish falls in
+	next := func(previous int) int {
+		n := polling.Next(previous)
+		if n <= previous {
+			n = previous + 1
+		}
+		if n > check.Threshold {
+			n = check.Threshold
+		}
+		return n
+	}
+	lower, upper := 0, next(0)
+	skipped := 0
+	for float64(upper) < secondsSincePublish && upper < check.Threshold {
+		lower, upper = upper, next(upper)
+		skipped++
+	}
 	check.log.Infof("Checking %s. Skipping first [%v] checks",
 		LoggingContextForCheck(check.Metric.Config.Alias,
 			check.Metric.UUID,
 			check.Metric.Platform,
 			check.Metric.TID),
-		int(elapsedIntervals))
-
-	checkNr := int(elapsedIntervals) + 1
-	// ticker to fire once per interval
-	tickerChan := time.NewTicker(time.Duration(check.CheckInterval) * time.Second)
-	for {
-		checkSuccessful, ignoreCheck := check.DoCheck()
-		if ignoreCheck {
-			check.log.Infof("Ignore check for %s",
-				LoggingContextForCheck(check.Metric.Config.Alias,
-					check.Metric.UUID,
-					check.Metric.Platform,
-					check.Metric.TID))
-			tickerChan.Stop()
-			return
-		}
-
-		lower := (checkNr - 1) * check.CheckInterval
-		upper := checkNr * check.CheckInterval
-		check.Metric.PublishInterval = metrics.Interval{
-			LowerBound: lower,
-			UpperBound: upper,
+		skipped)
+
+	run := &checkRun{
+		ctx:             ctx,
+		span:            span,
+		check:           check,
+		metricContainer: metricContainer,
+		publishEvents:   publishEvents,
+		inFlight:        inFlight,
+		checkID:         checkID,
+		cancelled:       cancelled,
+		release:         func() { stopInterrupt(); cancel() },
+		halted:          interrupt.Done(),
+		next:            next,
+		lower:           lower,
+		upper:           upper,
+		index:           -1,
+	}
+	if check.resumedLate {
+		// the late tracking checks are scheduled in seconds since publish, from the SLA up to the hard limit
+		run.lateTracking = true
+		run.lower, run.upper = check.Threshold, run.nextLate(check.Threshold)
+		for float64(run.upper) < secondsSincePublish && run.upper < check.HardLimit {
+			run.lower, run.upper = run.upper, run.nextLate(run.upper)
+		}
+	}
+	return run
+}
+
+// attempt checks the endpoint once and reports the outcome if the check is over.
+// It returns when the next attempt is due, or false if the check is over.
+func (r *checkRun) attempt() (time.Time, bool) {
+	select {
+	case <-r.cancelled:
+		r.cancel()
+		r.end()
+		return time.Time{}, false
+	default:
+	}
+
+	select {
+	case <-r.halted:
+		r.interrupt()
+		r.end()
+		return time.Time{}, false
+	default:
+	}
+
+	var due time.Time
+	var more bool
+	if r.lateTracking {
+		due, more = r.trackLate()
+	} else {
+		due, more = r.poll()
+	}
+	if !more {
+		r.end()
+	}
+	return due, more
+}
+
+// poll runs a check before the SLA.
+func (r *checkRun) poll() (time.Time, bool) {
+	check := &r.check
+	checkSuccessful, ignoreCheck := check.DoCheck(r.ctx)
+	checkedAt := time.Now()
+	if r.halting(checkSuccessful) {
+		return time.Time{}, false
+	}
+	r.inFlight.attempted(r.checkID)
+	r.attempts++
+	r.span.SetAttributes(attribute.Int("attempts", r.attempts))
+	if ignoreCheck {
+		check.log.Infof("Ignore check for %s",
+			LoggingContextForCheck(check.Metric.Config.Alias,
+				check.Metric.UUID,
+				check.Metric.Platform,
+				check.Metric.TID))
+		check.Metric.Outcome = metrics.OutcomeIgnored
+		r.span.SetAttributes(attribute.String("outcome", string(metrics.OutcomeIgnored)))
+		r.publishEvents.completed(check.Metric, CheckIgnored)
+		// ignored checks are counted by the destinations but are not part of the publish history
+		check.ResultSink <- check.Metric
+		return time.Time{}, false
+	}
+
+	check.Metric.PublishInterval = metrics.Interval{
+		LowerBound: r.lower,
+		UpperBound: r.upper,
+	}
+
+	if checkSuccessful {
+		check.Metric.ObservedAt = checkedAt
+		check.Metric.PublishOK = true
+		check.Metric.Outcome = metrics.OutcomeSuccess
+		r.span.SetAttributes(
+			attribute.String("outcome", string(metrics.OutcomeSuccess)),
+			attribute.Int("publish_interval.upper_bound", r.upper),
+		)
+		r.publishEvents.completed(check.Metric, CheckSucceeded)
+
+		check.ResultSink <- check.Metric
+		r.metricContainer.Update(check.Metric)
+		return time.Time{}, false
+	}
+
+	if r.upper >= check.Threshold && check.inconclusive != "" {
+		// the endpoint could not be checked, so whether the publish failed is unknown
+		check.log.Infof("Inconclusive check for %s, reason [%s]", check, check.inconclusive)
+		check.Metric.PublishOK = false
+		check.Metric.Outcome = metrics.OutcomeInconclusive
+		check.Metric.Reason = check.inconclusive
+		check.Metric.Attempts = check.lastAttempts()
+		r.span.SetAttributes(
+			attribute.String("outcome", string(metrics.OutcomeInconclusive)),
+			attribute.String("inconclusive_reason", string(check.inconclusive)),
+		)
+		r.publishEvents.completed(check.Metric, CheckInconclusive)
+		check.ResultSink <- check.Metric
+		r.metricContainer.Update(check.Metric)
+		return time.Time{}, false
+	}
+
+	if r.upper >= check.Threshold {
+		// if we get here, checks were unsuccessful
+		check.Metric.PublishOK = false
+		check.Metric.Outcome = metrics.OutcomeFailure
+		check.Metric.Attempts = check.lastAttempts()
+		r.span.SetAttributes(attribute.String("outcome", string(metrics.OutcomeFailure)))
+		r.span.SetStatus(codes.Error, "content not available within the SLA")
+		r.publishEvents.completed(check.Metric, CheckFailed)
+		check.ResultSink <- check.Metric
+		r.metricContainer.Update(check.Metric)
+
+		if check.HardLimit <= check.Threshold {
+			return time.Time{}, false
 		}
+		check.log.Infof("Tracking late publish for %s until [%v] seconds after publish", check, check.HardLimit)
+		r.lateTracking = true
+		r.lower, r.upper = r.upper, r.nextLate(r.upper)
+		return r.dueAt(r.upper), true
+	}
 
-		if checkSuccessful {
-			tickerChan.Stop()
-			check.Metric.PublishOK = true
+	r.lower, r.upper = r.upper, r.next(r.upper)
+	return r.dueAt(r.upper), true
+}
 
-			check.ResultSink <- check.Metric
-			metricContainer.Update(check.Metric)
-			return
+// trackLate runs a check after the SLA, and reports whether the publish eventually became available,
+// and how late, or was lost by the hard limit of the check.
+// The failure of the check has already been reported, so the outcome is not part of the publish history.
+func (r *checkRun) trackLate() (time.Time, bool) {
+	check := &r.check
+	checkSuccessful, ignoreCheck := check.DoCheck(r.ctx)
+	checkedAt := time.Now()
+	if r.halting(checkSuccessful) {
+		return time.Time{}, false
+	}
+	r.inFlight.attempted(r.checkID)
+	if ignoreCheck {
+		check.log.Infof("Ignore late tracking for %s", check)
+		return time.Time{}, false
+	}
+
+	if checkSuccessful {
+		publishSLA := check.Metric.PublishDate.Add(time.Duration(check.Threshold) * time.Second)
+		check.Metric.PublishInterval = metrics.Interval{
+			LowerBound: r.lower,
+			UpperBound: r.upper,
+		}
+		check.Metric.ObservedAt = checkedAt
+		check.Metric.PublishOK = true
+		check.Metric.Outcome = metrics.OutcomeLate
+		check.Metric.Attempts = nil
+		check.Metric.Lateness = checkedAt.Sub(publishSLA)
+		if latency, found := check.Metric.Latency(); found {
+			check.Metric.Lateness = latency - time.Duration(check.Threshold)*time.Second
 		}
+		if check.Metric.Lateness < 0 {
+			check.Metric.Lateness = 0 // the notification was received before the SLA but the content was not readable
+		}
+		check.log.Infof("Late publish for %s, available [%v] after the SLA", check, check.Metric.Lateness)
+		r.span.SetAttributes(attribute.String("late_tracking.outcome", string(metrics.OutcomeLate)))
+		check.ResultSink <- check.Metric
+		return time.Time{}, false
+	}
 
-		checkNr++
+	if r.upper >= check.HardLimit {
+		check.Metric.PublishInterval = metrics.Interval{
+			LowerBound: check.Threshold,
+			UpperBound: check.HardLimit,
+		}
+		check.Metric.PublishOK = false
+		check.Metric.Outcome = metrics.OutcomeLost
+		check.Metric.Attempts = check.lastAttempts()
+		check.log.Infof("Lost publish for %s, not available [%v] seconds after publish", check, check.HardLimit)
+		r.span.SetAttributes(attribute.String("late_tracking.outcome", string(metrics.OutcomeLost)))
+		check.ResultSink <- check.Metric
+		return time.Time{}, false
+	}
 
-		select {
-		case <-tickerChan.C:
-			continue
-		case <-quitChan:
-			tickerChan.Stop()
-			// if we get here, checks were unsuccessful
-			check.Metric.PublishOK = false
-			check.ResultSink <- check.Metric
-			metricContainer.Update(check.Metric)
-			return
+	r.lower, r.upper = r.upper, r.nextLate(r.upper)
+	return r.dueAt(r.upper), true
+}
+
+// nextLate returns when the late tracking check following the one at previous runs, up to the hard limit.
+func (r *checkRun) nextLate(previous int) int {
+	interval := r.check.LateCheckInterval
+	if interval <= 0 {
+		interval = r.check.CheckInterval
+	}
+	if interval <= 0 {
+		interval = 1
+	}
+	if previous+interval > r.check.HardLimit {
+		return r.check.HardLimit
+	}
+	return previous + interval
+}
+
+// state returns what is checkpointed of the check, as of its last attempt.
+func (r *checkRun) state() CheckState {
+	check := &r.check
+	metric := check.Metric
+	metric.Attempts = check.lastAttempts()
+	return CheckState{
+		Metric:            metric,
+		Threshold:         check.Threshold,
+		CheckInterval:     check.CheckInterval,
+		HardLimit:         check.HardLimit,
+		LateCheckInterval: check.LateCheckInterval,
+		LateTracking:      r.lateTracking,
+	}
+}
+
+// dueAt returns the date of the check at the given seconds since publish.
+func (r *checkRun) dueAt(secondsSincePublish int) time.Time {
+	return r.check.Metric.PublishDate.Add(time.Duration(secondsSincePublish) * time.Second)
+}
+
+func (r *checkRun) cancel() {
+	if r.lateTracking {
+		r.check.log.Infof("Cancelled late tracking for %s", r.check)
+		return
+	}
+
+	r.check.log.Infof("Cancelled check for %s",
+		LoggingContextForCheck(r.check.Metric.Config.Alias,
+			r.check.Metric.UUID,
+			r.check.Metric.Platform,
+			r.check.Metric.TID))
+	r.span.SetAttributes(attribute.String("outcome", string(CheckCancelled)))
+	r.publishEvents.completed(r.check.Metric, CheckCancelled)
+}
+
+// halting interrupts the check if the monitor is shutting down, and tells whether it did.
+// An attempt cancelled by the shutdown tells nothing about the publish, and a check checkpointed to be resumed
+// stops whatever the outcome of its attempt, which is reported once resumed.
+func (r *checkRun) halting(checkSuccessful bool) bool {
+	select {
+	case <-r.suspended:
+	default:
+		if checkSuccessful || r.ctx.Err() == nil {
+			return false
 		}
 	}
+	r.interrupt()
+	return true
+}
+
+// interrupt reports that the check could not finish because the monitor is shutting down,
+// unless the check was checkpointed, as it reports its outcome once resumed after the restart.
+// Like the other late tracking outcomes, the interruption of late tracking is not part of the publish history.
+func (r *checkRun) interrupt() {
+	check := &r.check
+	select {
+	case <-r.suspended:
+		check.log.Infof("Suspended check for %s until the restart", check)
+		r.span.SetAttributes(attribute.String("outcome", "suspended"))
+		return
+	default:
+	}
+
+	r.interrupted = true
+	check.Metric.PublishOK = false
+	check.Metric.Outcome = metrics.OutcomeInterrupted
+	check.Metric.Attempts = check.lastAttempts()
+
+	if r.lateTracking {
+		check.log.Infof("Interrupted late tracking for %s", check)
+		r.span.SetAttributes(attribute.String("late_tracking.outcome", string(metrics.OutcomeInterrupted)))
+		check.ResultSink <- check.Metric
+		return
+	}
+
+	check.log.Infof("Interrupted check for %s", check)
+	check.Metric.PublishInterval = metrics.Interval{
+		LowerBound: r.lower,
+		UpperBound: r.upper,
+	}
+	r.span.SetAttributes(attribute.String("outcome", string(metrics.OutcomeInterrupted)))
+	r.publishEvents.completed(check.Metric, CheckInterrupted)
+	check.ResultSink <- check.Metric
+	r.metricContainer.Update(check.Metric)
+}
+
+func (r *checkRun) end() {
+	r.inFlight.remove(r.checkID)
+	r.release()
+	r.span.End()
 }
 
 func strSliceContains(slice []string, str string) bool {
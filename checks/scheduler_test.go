@@ -1,6 +1,8 @@
 package checks
 
 import (
+	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -8,7 +10,9 @@ import (
 	"github.com/Financial-Times/publish-availability-monitor/config"
 	"github.com/Financial-Times/publish-availability-monitor/content"
 	"github.com/Financial-Times/publish-availability-monitor/envs"
+	"github.com/Financial-Times/publish-availability-monitor/httpcaller"
 	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -104,6 +108,96 @@ func TestScheduleChecksForContentWithInternalComponentsAreCorrect(testing *testi
 	require.Equal(testing, readURL+"/internalcomponents/", capturingMetrics.First().Endpoint.String())
 }
 
+func TestScheduleChecksRestrictedByFilter(t *testing.T) {
+	appConfig := &config.AppConfig{
+		MetricConf: []config.MetricConfig{
+			{
+				Endpoint:     "/content/",
+				Granularity:  1,
+				Alias:        "content",
+				ContentTypes: []string{"application/vnd.ft-upp-image+json"},
+			},
+			{
+				Endpoint:     "/enrichedcontent/",
+				Granularity:  1,
+				Alias:        "enrichedContent",
+				ContentTypes: []string{"application/vnd.ft-upp-image+json"},
+			},
+		},
+		Threshold: 1,
+	}
+
+	environments := envs.NewEnvironments()
+	environments.SetEnvironment("env1", envs.Environment{Name: "env1", ReadURL: "http://env1.example.org"})
+	environments.SetEnvironment("env2", envs.Environment{Name: "env2", ReadURL: "http://env2.example.org"})
+
+	history := metrics.NewHistory(make([]metrics.PublishMetric, 0))
+	param := &SchedulerParam{
+		contentToCheck: content.GenericContent{UUID: "e28b12f7-9796-3331-b030-05082f0b8157", Type: "application/vnd.ft-upp-image+json"},
+		publishDate:    time.Now().Add(-time.Minute),
+		tid:            "tid_1234",
+		environments:   environments,
+	}
+	param.Restrict(CheckFilter{Endpoints: []string{"enrichedContent"}, Environments: []string{"env2"}})
+
+	scheduled := ScheduleChecks(
+		context.Background(),
+		param,
+		map[string]EndpointSpecificCheck{},
+		appConfig,
+		SchedulerDeps{MetricSink: make(chan metrics.PublishMetric, 2), History: history, Scheduler: startScheduler(t)},
+		nil,
+		logger.NewUPPLogger("test", "PANIC"),
+	)
+
+	assert.Equal(t, 1, scheduled)
+	require.Eventually(t, func() bool { return history.Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "http://env2.example.org/enrichedcontent/", history.First().Endpoint.String())
+}
+
+func TestScheduleChecksAfterDrain(t *testing.T) {
+	appConfig := &config.AppConfig{
+		MetricConf: []config.MetricConfig{
+			{Endpoint: "/content/", Granularity: 1, Alias: "content", ContentTypes: []string{"application/vnd.ft-upp-image+json"}},
+		},
+		Threshold: 1,
+	}
+	withEnvironment := envs.NewEnvironments()
+	withEnvironment.SetEnvironment("env1", envs.Environment{Name: "env1", ReadURL: "http://env1.example.org"})
+
+	log := logger.NewUPPLogger("test", "PANIC")
+	scheduler := NewCheckScheduler(config.SchedulerConfig{}, log)
+	go scheduler.Run()
+	scheduler.Drain(context.Background())
+
+	// the metric sink is closed once the scheduler is drained
+	metricSink := make(chan metrics.PublishMetric)
+	close(metricSink)
+
+	for name, environments := range map[string]*envs.Environments{"environments": withEnvironment, "no environment": envs.NewEnvironments()} {
+		t.Run(name, func(t *testing.T) {
+			history := metrics.NewHistory(make([]metrics.PublishMetric, 0))
+			param := &SchedulerParam{
+				contentToCheck: content.GenericContent{UUID: "e28b12f7-9796-3331-b030-05082f0b8157", Type: "application/vnd.ft-upp-image+json"},
+				publishDate:    time.Now(),
+				tid:            "tid_1234",
+				environments:   environments,
+			}
+
+			scheduled := ScheduleChecks(context.Background(), param, map[string]EndpointSpecificCheck{}, appConfig, SchedulerDeps{
+				MetricSink:    metricSink,
+				History:       history,
+				PublishEvents: NewPublishEvents(10),
+				InFlight:      NewInFlightChecks(),
+				Scheduler:     scheduler,
+			}, nil, log)
+
+			assert.Zero(t, scheduled)
+			assert.Zero(t, history.Len(), "nothing should be reported once the scheduler is drained")
+		})
+	}
+}
+
 func runScheduleChecks(t *testing.T, content content.Content, mockEnvironments *envs.Environments, appConfig *config.AppConfig) *metrics.History {
 	capturingMetrics := metrics.NewHistory(make([]metrics.PublishMetric, 0))
 
@@ -119,7 +213,6 @@ func runScheduleChecks(t *testing.T, content content.Content, mockEnvironments *
 		publishDate:     publishDate,
 		tid:             tid,
 		isMarkedDeleted: true,
-		metricContainer: capturingMetrics,
 		environments:    mockEnvironments,
 	}
 	//redefine map to avoid actual checks
@@ -129,10 +222,11 @@ func runScheduleChecks(t *testing.T, content content.Content, mockEnvironments *
 	log := logger.NewUPPLogger("test", "PANIC")
 
 	ScheduleChecks(
+		context.Background(),
 		param,
 		endpointSpecificChecks,
 		appConfig,
-		metricSink,
+		SchedulerDeps{MetricSink: metricSink, History: capturingMetrics, Scheduler: startScheduler(t)},
 		nil,
 		log,
 	)
@@ -144,3 +238,237 @@ func runScheduleChecks(t *testing.T, content content.Content, mockEnvironments *
 		time.Sleep(1 * time.Second)
 	}
 }
+
+func TestScheduleCheckTracksLatePublishes(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 2)
+	history := metrics.NewHistory(make([]metrics.PublishMetric, 0))
+
+	pm := metrics.PublishMetric{
+		UUID:        "uuid-1",
+		TID:         "tid_1",
+		PublishDate: time.Now().Add(-2 * time.Second),
+		Platform:    "eu",
+		Config:      config.MetricConfig{Alias: "content"},
+	}
+	check := NewPublishCheck(pm, "", "", 2, 1, metricSink, map[string]EndpointSpecificCheck{"content": &finishedAfterCheck{attempts: 3}}, log)
+	check.HardLimit = 10
+
+	startScheduler(t).schedule(context.Background(), *check, history, NewPublishEvents(10), NewInFlightChecks())
+
+	failure := receiveMetric(t, metricSink)
+	assert.Equal(t, metrics.OutcomeFailure, failure.Outcome)
+	assert.NotEmpty(t, failure.Attempts, "failures should come with the attempts of the check")
+
+	late := receiveMetric(t, metricSink)
+	assert.Equal(t, metrics.OutcomeLate, late.Outcome)
+	assert.True(t, late.PublishOK)
+	assert.Equal(t, metrics.Interval{LowerBound: 3, UpperBound: 4}, late.PublishInterval)
+	assert.InDelta(t, 2*time.Second, late.Lateness, float64(500*time.Millisecond))
+	assert.Empty(t, late.Attempts)
+
+	require.Equal(t, 1, history.Len(), "late tracking outcomes should not be part of the publish history")
+	assert.Equal(t, metrics.OutcomeFailure, history.First().Outcome)
+}
+
+func TestScheduleCheckReportsLostPublishes(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 2)
+
+	pm := metrics.PublishMetric{
+		UUID:        "uuid-1",
+		TID:         "tid_1",
+		PublishDate: time.Now().Add(-time.Second),
+		Platform:    "eu",
+		Config:      config.MetricConfig{Alias: "content"},
+	}
+	check := NewPublishCheck(pm, "", "", 1, 1, metricSink, map[string]EndpointSpecificCheck{"content": neverFinishedCheck{}}, log)
+	check.HardLimit = 2
+
+	startScheduler(t).schedule(context.Background(), *check, metrics.NewHistory(nil), NewPublishEvents(10), NewInFlightChecks())
+
+	assert.Equal(t, metrics.OutcomeFailure, receiveMetric(t, metricSink).Outcome)
+
+	lost := receiveMetric(t, metricSink)
+	assert.Equal(t, metrics.OutcomeLost, lost.Outcome)
+	assert.False(t, lost.PublishOK)
+	assert.Equal(t, metrics.Interval{LowerBound: 1, UpperBound: 2}, lost.PublishInterval)
+}
+
+func TestScheduleCheckIsInconclusiveWhenCircuitIsOpen(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 1)
+	history := metrics.NewHistory(make([]metrics.PublishMetric, 0))
+	publishEvents := NewPublishEvents(10)
+
+	pm := metrics.PublishMetric{
+		UUID:        "uuid-1",
+		TID:         "tid_1",
+		PublishDate: time.Now().Add(-time.Second),
+		Platform:    "eu",
+		Config:      config.MetricConfig{Alias: "content"},
+	}
+	endpointChecks := map[string]EndpointSpecificCheck{"content": NewContentCheck(failingHTTPCaller{httpcaller.ErrCircuitOpen})}
+	check := NewPublishCheck(pm, "", "", 1, 1, metricSink, endpointChecks, log)
+	check.HardLimit = 10
+
+	startScheduler(t).schedule(context.Background(), *check, history, publishEvents, NewInFlightChecks())
+
+	inconclusive := receiveMetric(t, metricSink)
+	assert.Equal(t, metrics.OutcomeInconclusive, inconclusive.Outcome)
+	assert.Equal(t, metrics.ReasonCircuitOpen, inconclusive.Reason)
+	assert.False(t, inconclusive.PublishOK)
+	assert.Empty(t, history.GetFailures(), "inconclusive checks should not count as failures")
+
+	select {
+	case pm := <-metricSink:
+		assert.Fail(t, "late publishes should not be tracked for inconclusive checks", "got %v", pm.Outcome)
+	case <-time.After(1500 * time.Millisecond):
+	}
+}
+
+// statusHTTPCaller responds with the status codes in order, then with the last one
+type statusHTTPCaller struct {
+	statusCodes []int
+	calls       int
+}
+
+func (c *statusHTTPCaller) DoCall(_ context.Context, _ httpcaller.Config) (*http.Response, error) {
+	statusCode := c.statusCodes[min(c.calls, len(c.statusCodes)-1)]
+	c.calls++
+	return buildResponse(statusCode, ""), nil
+}
+
+func TestScheduleCheckOutcomeOfLastAttempt(t *testing.T) {
+	tests := map[string]struct {
+		StatusCodes     []int
+		ExpectedOutcome metrics.Outcome
+		ExpectedReason  metrics.InconclusiveReason
+	}{
+		"server error after the content was not found yet": {
+			StatusCodes:     []int{http.StatusNotFound, http.StatusNotFound, http.StatusServiceUnavailable},
+			ExpectedOutcome: metrics.OutcomeInconclusive,
+			ExpectedReason:  metrics.ReasonServerError,
+		},
+		"content not found after server errors": {
+			StatusCodes:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusNotFound},
+			ExpectedOutcome: metrics.OutcomeFailure,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			log := logger.NewUPPLogger("test", "PANIC")
+			metricSink := make(chan metrics.PublishMetric, 1)
+			history := metrics.NewHistory(make([]metrics.PublishMetric, 0))
+
+			pm := metrics.PublishMetric{
+				UUID:        "uuid-1",
+				TID:         "tid_1",
+				PublishDate: time.Now().Add(-time.Second),
+				Platform:    "eu",
+				Config:      config.MetricConfig{Alias: "content"},
+			}
+			caller := &statusHTTPCaller{statusCodes: test.StatusCodes}
+			check := NewPublishCheck(pm, "", "", 4, 1, metricSink, map[string]EndpointSpecificCheck{"content": NewContentCheck(caller)}, log)
+
+			startScheduler(t).schedule(context.Background(), *check, history, NewPublishEvents(10), NewInFlightChecks())
+
+			pm = receiveMetric(t, metricSink)
+			require.Equal(t, 3, caller.calls)
+			assert.Equal(t, test.ExpectedOutcome, pm.Outcome, "the last attempt before the SLA should decide the outcome")
+			assert.Equal(t, test.ExpectedReason, pm.Reason)
+			assert.Equal(t, test.StatusCodes[2], pm.Attempts[len(pm.Attempts)-1].StatusCode)
+		})
+	}
+}
+
+func TestResumeChecks(t *testing.T) {
+	log := logger.NewUPPLogger("test", "PANIC")
+	metricSink := make(chan metrics.PublishMetric, 2)
+	publishEvents := NewPublishEvents(10)
+	environments := envs.NewEnvironments()
+	environments.SetEnvironment("eu", envs.Environment{Name: "eu", ReadURL: "https://eu.ft.com"})
+	appConfig := &config.AppConfig{
+		MetricConf: []config.MetricConfig{{Alias: "content", Endpoint: "/content/", Granularity: 1}},
+	}
+
+	states := []CheckState{
+		{
+			Metric: metrics.PublishMetric{
+				UUID:        "uuid-1",
+				TID:         "tid_1",
+				ContentType: "article",
+				PublishDate: time.Now().Add(-time.Second),
+				Platform:    "eu",
+				Config:      config.MetricConfig{Alias: "content"},
+				Attempts:    []metrics.CheckAttempt{{StatusCode: 404}},
+			},
+			Threshold:     3,
+			CheckInterval: 1,
+		},
+		{
+			// the failure was reported before the restart
+			Metric: metrics.PublishMetric{
+				UUID:        "uuid-2",
+				TID:         "tid_2",
+				PublishDate: time.Now().Add(-2 * time.Second),
+				Platform:    "eu",
+				Outcome:     metrics.OutcomeFailure,
+				Config:      config.MetricConfig{Alias: "content"},
+			},
+			Threshold:     1,
+			CheckInterval: 1,
+			HardLimit:     3,
+			LateTracking:  true,
+		},
+		{
+			Metric:    metrics.PublishMetric{TID: "tid_3", PublishDate: time.Now(), Platform: "eu", Config: config.MetricConfig{Alias: "removed"}},
+			Threshold: 1,
+		},
+		{
+			Metric:    metrics.PublishMetric{TID: "tid_4", PublishDate: time.Now(), Platform: "removed", Config: config.MetricConfig{Alias: "content"}},
+			Threshold: 1,
+		},
+	}
+
+	endpointChecks := map[string]EndpointSpecificCheck{"content": neverFinishedCheck{}}
+	resumed := ResumeChecks(context.Background(), states, endpointChecks, appConfig, environments, SchedulerDeps{
+		MetricSink:    metricSink,
+		History:       metrics.NewHistory(nil),
+		PublishEvents: publishEvents,
+		InFlight:      NewInFlightChecks(),
+		Scheduler:     startScheduler(t),
+	}, log)
+	assert.Equal(t, 2, resumed, "the checks of an endpoint or environment no longer configured should be dropped")
+
+	outcomes := make(map[string]metrics.PublishMetric)
+	for i := 0; i < 2; i++ {
+		pm := receiveMetric(t, metricSink)
+		outcomes[pm.TID] = pm
+	}
+
+	failure := outcomes["tid_1"]
+	assert.Equal(t, metrics.OutcomeFailure, failure.Outcome)
+	assert.Equal(t, metrics.Interval{LowerBound: 2, UpperBound: 3}, failure.PublishInterval)
+	assert.Equal(t, "/content/", failure.Config.Endpoint, "the config of the endpoint should be the current one")
+	assert.Len(t, failure.Attempts, 3, "the attempts before the restart should be kept")
+	event, found := publishEvents.Get("tid_1")
+	require.True(t, found)
+	assert.Equal(t, PublishFailed, event.Status)
+
+	assert.Equal(t, metrics.OutcomeLost, outcomes["tid_2"].Outcome, "late tracking should carry on")
+	_, found = publishEvents.Get("tid_2")
+	assert.False(t, found, "late tracking is not part of the publish checks")
+}
+
+func receiveMetric(t *testing.T, metricSink chan metrics.PublishMetric) metrics.PublishMetric {
+	t.Helper()
+	select {
+	case metric := <-metricSink:
+		return metric
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no metric received")
+		return metrics.PublishMetric{}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Financial-Times/kafka-client-go/v4"
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/google/uuid"
+)
+
+const defaultManualCheckOriginSystemID = "http://cmdb.ft.com/systems/cct"
+
+// messageChecker schedules the checks of a publish message which was not consumed.
+type messageChecker interface {
+	CheckMessage(ctx context.Context, msg kafka.FTMessage, filter checks.CheckFilter) (int, error)
+}
+
+// checkRequest describes a publish to check as if its message was consumed.
+type checkRequest struct {
+	UUID           string          `json:"uuid"`
+	ContentType    string          `json:"contentType"`
+	TID            string          `json:"transactionId,omitempty"`  // generated if missing
+	PublishDate    *time.Time      `json:"publishDate,omitempty"`    // now if missing
+	OriginSystemID string          `json:"originSystemId,omitempty"` // cct if missing
+	Content        json.RawMessage `json:"content,omitempty"`        // the published content, {"uuid": uuid} if missing
+	checks.CheckFilter
+}
+
+type checkResponse struct {
+	TID       string `json:"transactionId"`
+	UUID      string `json:"uuid"`
+	Scheduled int    `json:"scheduled"`
+	Publish   string `json:"publish"` // where to poll the results of the checks
+}
+
+// triggerChecks schedules the checks of the publish described by the request body,
+// running the same pre-checks as for a consumed message.
+func triggerChecks(checker messageChecker) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req checkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "cannot decode request body: "+err.Error())
+			return
+		}
+		if req.UUID == "" || req.ContentType == "" {
+			writeJSONError(w, http.StatusBadRequest, "uuid and contentType are required")
+			return
+		}
+		if _, err := uuid.Parse(req.UUID); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid uuid: "+err.Error())
+			return
+		}
+
+		msg := req.message()
+		tid := msg.Headers["X-Request-Id"]
+
+		// the checks outlive the request
+		scheduled, err := checker.CheckMessage(context.WithoutCancel(r.Context()), msg, req.CheckFilter)
+		switch {
+		case errors.Is(err, errInvalidMessage):
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		case errors.Is(err, errShuttingDown):
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		case err != nil:
+			writeJSONError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		case scheduled == 0:
+			writeJSONError(w, http.StatusUnprocessableEntity, "no check is configured for the content type, endpoints and environments")
+			return
+		}
+
+		publish := "/__publishes/" + tid
+		w.Header().Set("Location", publish)
+		writeJSON(w, http.StatusAccepted, checkResponse{
+			TID:       tid,
+			UUID:      req.UUID,
+			Scheduled: scheduled,
+			Publish:   publish,
+		})
+	}
+}
+
+// message returns the publish message the request stands for.
+func (req checkRequest) message() kafka.FTMessage {
+	tid := req.TID
+	if tid == "" {
+		tid = "tid_pam_manual_" + uuid.NewString()
+	}
+
+	publishDate := time.Now()
+	if req.PublishDate != nil {
+		publishDate = *req.PublishDate
+	}
+
+	originSystemID := req.OriginSystemID
+	if originSystemID == "" {
+		originSystemID = defaultManualCheckOriginSystemID
+	}
+
+	body := string(req.Content)
+	if body == "" {
+		body = `{"uuid":"` + req.UUID + `"}`
+	}
+
+	return kafka.NewFTMessage(map[string]string{
+		"X-Request-Id":      tid,
+		"Message-Timestamp": publishDate.UTC().Format(checks.DateLayout),
+		"Content-Type":      req.ContentType,
+		systemIDKey:         originSystemID,
+	}, body)
+}
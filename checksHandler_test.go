@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/kafka-client-go/v4"
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/envs"
+	"github.com/Financial-Times/publish-availability-monitor/feeds"
+	"github.com/Financial-Times/publish-availability-monitor/httpcaller"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturingChecker struct {
+	msg       kafka.FTMessage
+	filter    checks.CheckFilter
+	scheduled int
+	err       error
+}
+
+func (c *capturingChecker) CheckMessage(_ context.Context, msg kafka.FTMessage, filter checks.CheckFilter) (int, error) {
+	c.msg = msg
+	c.filter = filter
+	return c.scheduled, c.err
+}
+
+func TestTriggerChecks(t *testing.T) {
+	checker := &capturingChecker{scheduled: 2}
+	body := `{
+		"uuid": "077f5ac2-0491-420e-a5d0-982e0f86204b",
+		"contentType": "application/vnd.ft-upp-article+json",
+		"transactionId": "tid_manual",
+		"publishDate": "2024-01-02T03:04:05.678Z",
+		"endpoints": ["content"],
+		"environments": ["eu"]
+	}`
+
+	req := httptest.NewRequest(http.MethodPost, "/__checks", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	triggerChecks(checker)(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/__publishes/tid_manual", w.Header().Get("Location"))
+
+	var resp checkResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, checkResponse{
+		TID:       "tid_manual",
+		UUID:      "077f5ac2-0491-420e-a5d0-982e0f86204b",
+		Scheduled: 2,
+		Publish:   "/__publishes/tid_manual",
+	}, resp)
+
+	assert.Equal(t, map[string]string{
+		"X-Request-Id":      "tid_manual",
+		"Message-Timestamp": "2024-01-02T03:04:05.678Z",
+		"Content-Type":      "application/vnd.ft-upp-article+json",
+		"Origin-System-Id":  "http://cmdb.ft.com/systems/cct",
+	}, checker.msg.Headers)
+	assert.JSONEq(t, `{"uuid":"077f5ac2-0491-420e-a5d0-982e0f86204b"}`, checker.msg.Body)
+	assert.Equal(t, checks.CheckFilter{Endpoints: []string{"content"}, Environments: []string{"eu"}}, checker.filter)
+}
+
+func TestTriggerChecksGeneratesTransactionID(t *testing.T) {
+	checker := &capturingChecker{scheduled: 1}
+	body := `{"uuid": "077f5ac2-0491-420e-a5d0-982e0f86204b", "contentType": "application/vnd.ft-upp-article+json"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/__checks", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	triggerChecks(checker)(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	tid := checker.msg.Headers["X-Request-Id"]
+	assert.True(t, strings.HasPrefix(tid, "tid_pam_manual_"))
+	assert.Equal(t, "/__publishes/"+tid, w.Header().Get("Location"))
+	assert.NotEmpty(t, checker.msg.Headers["Message-Timestamp"])
+}
+
+func TestTriggerChecksErrors(t *testing.T) {
+	validBody := `{"uuid": "077f5ac2-0491-420e-a5d0-982e0f86204b", "contentType": "application/vnd.ft-upp-article+json"}`
+
+	tests := map[string]struct {
+		Body           string
+		Checker        *capturingChecker
+		ExpectedStatus int
+	}{
+		"invalid JSON": {
+			Body:           `{`,
+			Checker:        &capturingChecker{},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		"missing content type": {
+			Body:           `{"uuid": "077f5ac2-0491-420e-a5d0-982e0f86204b"}`,
+			Checker:        &capturingChecker{},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		"invalid uuid": {
+			Body:           `{"uuid": "not-a-uuid", "contentType": "application/vnd.ft-upp-article+json"}`,
+			Checker:        &capturingChecker{},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		"invalid message": {
+			Body:           validBody,
+			Checker:        &capturingChecker{err: errInvalidMessage},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		"publish not checked": {
+			Body:           validBody,
+			Checker:        &capturingChecker{err: errPublishNotChecked},
+			ExpectedStatus: http.StatusUnprocessableEntity,
+		},
+		"shutting down": {
+			Body:           validBody,
+			Checker:        &capturingChecker{err: errShuttingDown},
+			ExpectedStatus: http.StatusServiceUnavailable,
+		},
+		"no check scheduled": {
+			Body:           validBody,
+			Checker:        &capturingChecker{},
+			ExpectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/__checks", strings.NewReader(test.Body))
+			w := httptest.NewRecorder()
+			triggerChecks(test.Checker)(w, req)
+
+			assert.Equal(t, test.ExpectedStatus, w.Code)
+			assert.Empty(t, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestTriggerChecksOfPublishCheckedBefore(t *testing.T) {
+	const contentUUID = "077f5ac2-0491-420e-a5d0-982e0f86204b"
+	const contentType = "application/vnd.ft-upp-article-internal+json"
+
+	var republished atomic.Bool
+	readEnv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/validate":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/content/"+contentUUID && republished.Load():
+			_ = json.NewEncoder(w).Encode(map[string]string{"uuid": contentUUID, "publishReference": "tid_checked_before"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer readEnv.Close()
+
+	environments := envs.NewEnvironments()
+	environments.SetEnvironment("eu", envs.Environment{Name: "eu", ReadURL: readEnv.URL})
+	appConfig := &config.AppConfig{
+		Threshold:           1,
+		ValidationEndpoints: map[string]string{contentType: readEnv.URL + "/validate"},
+		MetricConf: []config.MetricConfig{
+			{Endpoint: "/content/", Granularity: 1, Alias: "content", ContentTypes: []string{contentType}},
+		},
+	}
+	log := logger.NewUPPLogger("test", "PANIC")
+	scheduler := checks.NewCheckScheduler(config.SchedulerConfig{}, log)
+	go scheduler.Run()
+	defer scheduler.Stop()
+
+	publishEvents := checks.NewPublishEvents(10)
+	handler := newKafkaMessageHandler(appConfig, environments, make(map[string][]feeds.Feed), MessageHandlerDeps{
+		SchedulerDeps: checks.SchedulerDeps{
+			MetricSink:    make(chan metrics.PublishMetric, 10),
+			History:       metrics.NewHistory(nil),
+			PublishEvents: publishEvents,
+			InFlight:      checks.NewInFlightChecks(),
+			Scheduler:     scheduler,
+		},
+		HTTPCaller: httpcaller.NewCaller(10),
+	}, nil, log)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/__checks", triggerChecks(handler)).Methods(http.MethodPost)
+	router.HandleFunc("/__publishes/{tid}", loadPublishEvent(publishEvents)).Methods(http.MethodGet)
+	publishEvent := func(path string) checks.PublishEvent {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var event checks.PublishEvent
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&event))
+		return event
+	}
+
+	// the content was not available when first published
+	scheduled, err := handler.handleMessage(kafka.NewFTMessage(map[string]string{
+		"X-Request-Id":      "tid_checked_before",
+		"Message-Timestamp": time.Now().UTC().Format(checks.DateLayout),
+		"Content-Type":      contentType,
+		systemIDKey:         defaultManualCheckOriginSystemID,
+	}, `{"uuid":"`+contentUUID+`"}`))
+	require.NoError(t, err)
+	require.Equal(t, 1, scheduled)
+	require.Eventually(t, func() bool {
+		return publishEvent("/__publishes/tid_checked_before").Status == checks.PublishFailed
+	}, 5*time.Second, 50*time.Millisecond)
+
+	republished.Store(true)
+	body := `{"uuid": "` + contentUUID + `", "contentType": "` + contentType + `", "transactionId": "tid_checked_before"}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/__checks", strings.NewReader(body)))
+	require.Equal(t, http.StatusAccepted, w.Code)
+	location := w.Header().Get("Location")
+	assert.Equal(t, "/__publishes/tid_checked_before", location)
+
+	var event checks.PublishEvent
+	require.Eventually(t, func() bool {
+		event = publishEvent(location)
+		return event.Status != checks.PublishInProgress
+	}, 5*time.Second, 50*time.Millisecond, "the publish checked again should complete")
+	require.Len(t, event.Checks, 2)
+	assert.Equal(t, checks.CheckFailed, event.Checks[0].Status)
+	assert.Equal(t, checks.CheckSucceeded, event.Checks[1].Status, "the manual check should complete its own check")
+}
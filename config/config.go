@@ -10,17 +10,28 @@ import (
 
 // AppConfig holds the application's configuration
 type AppConfig struct {
-	Threshold                               int               `json:"threshold"` // pub SLA in seconds, ex. 120
-	QueueConf                               QueueConfig       `json:"queueConfig"`
-	MetricConf                              []MetricConfig    `json:"metricConfig"`
-	SplunkConf                              SplunkConfig      `json:"splunk-config"`
-	HealthConf                              HealthConfig      `json:"healthConfig"`
-	ValidationEndpoints                     map[string]string `json:"validationEndpoints"` // contentType to validation endpoint mapping
-	Capabilities                            []Capability      `json:"capabilities"`
-	GraphiteAddress                         string            `json:"graphiteAddress"`
-	GraphiteUUID                            string            `json:"graphiteUUID"`
-	Environment                             string            `json:"environment"`
-	NotificationsPushPublicationMonitorList string            `json:"notificationsPushPublicationMonitorList"`
+	Threshold                               int                `json:"threshold"`             // pub SLA in seconds, ex. 120
+	ContentTypeThresholds                   map[string]int     `json:"contentTypeThresholds"` // contentType to pub SLA in seconds, overrides threshold
+	QueueConf                               QueueConfig        `json:"queueConfig"`
+	MetricConf                              []MetricConfig     `json:"metricConfig"`
+	SplunkConf                              SplunkConfig       `json:"splunk-config"`
+	HealthConf                              HealthConfig       `json:"healthConfig"`
+	HistoryConf                             HistoryConfig      `json:"historyConfig"`
+	TracingConf                             TracingConfig      `json:"tracingConfig"`
+	WebhookConf                             WebhookConfig      `json:"webhookConfig"`
+	ValidationEndpoints                     map[string]string  `json:"validationEndpoints"` // contentType to validation endpoint mapping
+	Capabilities                            []Capability       `json:"capabilities"`
+	GraphiteAddress                         string             `json:"graphiteAddress"`
+	GraphiteUUID                            string             `json:"graphiteUUID"`
+	GraphiteConf                            GraphiteConfig     `json:"graphiteConfig"`
+	StatsConf                               StatsConfig        `json:"statsConfig"`
+	SLAConf                                 SLAConfig          `json:"slaConfig"`
+	LateTrackingConf                        LateTrackingConfig `json:"lateTrackingConfig"`
+	SchedulerConf                           SchedulerConfig    `json:"schedulerConfig"`
+	ReadAPIConf                             ReadAPIConfig      `json:"readApiConfig"`
+	RecorderConf                            RecorderConfig     `json:"recorderConfig"`
+	Environment                             string             `json:"environment"`
+	NotificationsPushPublicationMonitorList string             `json:"notificationsPushPublicationMonitorList"`
 }
 
 // QueueConfig is the configuration for kafka consumer queue
@@ -34,22 +45,155 @@ type QueueConfig struct {
 
 // MetricConfig is the configuration of a PublishMetric
 type MetricConfig struct {
-	Granularity  int      `json:"granularity"` // how we split up the threshold, ex. 120/12
-	Endpoint     string   `json:"endpoint"`
-	ContentTypes []string `json:"contentTypes"` // list of valid types for this metric
-	Alias        string   `json:"alias"`
-	Health       string   `json:"health,omitempty"`
-	APIKey       string   `json:"apiKey,omitempty"`
+	Granularity  int            `json:"granularity"` // how we split up the threshold, ex. 120/12
+	Endpoint     string         `json:"endpoint"`
+	ContentTypes []string       `json:"contentTypes"` // list of valid types for this metric
+	Alias        string         `json:"alias"`
+	Health       string         `json:"health,omitempty"`
+	APIKey       string         `json:"apiKey,omitempty"`
+	Threshold    int            `json:"threshold,omitempty"` // pub SLA in seconds at this endpoint, overrides the content type and global ones
+	Polling      *PollingConfig `json:"polling,omitempty"`   // when the endpoint is checked, every threshold / granularity seconds if not present
+}
+
+// GetCheckInterval returns the seconds between the checks of the endpoint for a publish SLA of threshold seconds,
+// at least a second however fine the granularity. The threshold is not split up without a granularity.
+func (metric MetricConfig) GetCheckInterval(threshold int) int {
+	interval := threshold
+	if metric.Granularity > 0 {
+		interval = threshold / metric.Granularity
+	}
+	if interval < 1 {
+		return 1
+	}
+	return interval
+}
+
+// PollingConfig holds the configuration of the polling strategy of an endpoint
+type PollingConfig struct {
+	Strategy               string    `json:"strategy"`                         // fixed (default), exponential or frontloaded
+	InitialIntervalSeconds int       `json:"initialIntervalSeconds,omitempty"` // exponential: first interval, defaults to 1
+	Multiplier             float64   `json:"multiplier,omitempty"`             // exponential: growth of the interval, defaults to 2
+	MaxIntervalSeconds     int       `json:"maxIntervalSeconds,omitempty"`     // exponential: longest interval, defaults to threshold / granularity
+	Percentiles            []float64 `json:"percentiles,omitempty"`            // frontloaded: latency percentiles checked at, defaults to 10, 25, 50, 75, 90 and 95
+	MinSamples             int       `json:"minSamples,omitempty"`             // frontloaded: successful publishes in the history needed, defaults to 5
+}
+
+// LateTrackingConfig holds the configuration of the checks carried on after a publish missed its SLA,
+// to tell late publishes from lost ones
+type LateTrackingConfig struct {
+	HardLimitSeconds int `json:"hardLimitSeconds"`          // seconds since publish the checks stop at, late tracking is disabled if not above the threshold
+	IntervalSeconds  int `json:"intervalSeconds,omitempty"` // time between the checks, defaults to threshold / granularity
+}
+
+// SchedulerConfig holds the configuration of the pool of workers running the publish checks
+type SchedulerConfig struct {
+	Workers   int `json:"workers"`   // checks attempted at the same time, defaults to 100
+	MaxChecks int `json:"maxChecks"` // checks in progress before the consumption of publish events is held back, defaults to 10000
+	// seconds a shutdown waits for the checks in progress to finish before interrupting them, defaults to 20
+	DrainSeconds int `json:"drainSeconds"`
+	// file the checks in progress are checkpointed to, to resume them after a restart, not checkpointed if empty
+	CheckpointFile    string `json:"checkpointFile,omitempty"`
+	CheckpointSeconds int    `json:"checkpointSeconds"` // time between checkpoints, defaults to 10
+}
+
+// ReadAPIConfig holds the protection of the hosts of the read APIs against the calls of the checks
+type ReadAPIConfig struct {
+	RateLimit        float64 `json:"rateLimit"`        // calls per second to each host, unlimited if 0
+	Burst            int     `json:"burst"`            // calls to a host at once above the rate limit, defaults to the rate limit rounded up
+	FailureThreshold int     `json:"failureThreshold"` // consecutive failed calls opening the circuit of a host, the circuits never open if 0
+	OpenSeconds      int     `json:"openSeconds"`      // how long a circuit stays open before a trial call, defaults to 30
 }
 
 // SplunkConfig holds the SplunkFeeder-specific configuration
 type SplunkConfig struct {
-	LogPrefix string `json:"logPrefix"`
+	LogPrefix string          `json:"logPrefix"`
+	Format    string          `json:"format,omitempty"` // kv (default) for key=value lines or json for structured events
+	HEC       SplunkHECConfig `json:"hec"`
+}
+
+// SplunkHECConfig holds the configuration to send metrics straight to the Splunk HTTP Event Collector
+type SplunkHECConfig struct {
+	URL        string `json:"url,omitempty"` // HEC event endpoint, metrics are logged to stdout if empty
+	Token      string `json:"token,omitempty"`
+	Index      string `json:"index,omitempty"`
+	Source     string `json:"source,omitempty"`
+	SourceType string `json:"sourceType,omitempty"`
 }
 
 // HealthConfig holds the application's healthchecks configuration
 type HealthConfig struct {
-	FailureThreshold int `json:"failureThreshold"`
+	FailureThreshold int         `json:"failureThreshold"`
+	SLOs             []SLOConfig `json:"slos"` // each SLO is reported by its own healthcheck
+}
+
+// SLOConfig is a service level objective on the share of publishes meeting the SLA
+type SLOConfig struct {
+	Name        string  `json:"name"`
+	Objective   float64 `json:"objective"`             // percentage of publishes which should meet the SLA, ex. 99
+	Window      string  `json:"window,omitempty"`      // period of the error budget, ex. 7d, defaults to 30d
+	ContentType string  `json:"contentType,omitempty"` // only count the publishes of this content type
+	Endpoint    string  `json:"endpoint,omitempty"`    // only count the publishes checked at the endpoint with this alias
+	Environment string  `json:"environment,omitempty"` // only count the publishes checked in this environment
+}
+
+// HistoryConfig holds the configuration of the publish history
+type HistoryConfig struct {
+	FilePath    string `json:"filePath,omitempty"` // file the history is persisted to, kept only in memory if empty
+	MaxEntries  int    `json:"maxEntries"`         // number of publish metrics retained
+	MaxAgeHours int    `json:"maxAgeHours"`        // publish metrics older than this are discarded, 0 keeps them regardless of age
+}
+
+// RecorderConfig holds the recording of the consumed messages, to replay them later
+type RecorderConfig struct {
+	Dir            string   `json:"dir,omitempty"`           // directory of the archive, the messages are not recorded if empty
+	MaxFileSizeMB  int      `json:"maxFileSizeMB"`           // size a file is rotated at, defaults to 64
+	MaxFileMinutes int      `json:"maxFileMinutes"`          // age a file is rotated at, defaults to 60
+	MaxSizeMB      int      `json:"maxSizeMB"`               // size of the archive above which the oldest files are deleted, defaults to 1024
+	MaxAgeHours    int      `json:"maxAgeHours"`             // files older than this are deleted, 0 keeps them regardless of age
+	RedactHeaders  []string `json:"redactHeaders,omitempty"` // headers whose values are not recorded, on top of Authorization, Cookie and X-Api-Key
+}
+
+// TracingConfig holds the OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Exporter string `json:"exporter,omitempty"` // otlp, stdout or file, tracing is disabled if empty
+	Endpoint string `json:"endpoint,omitempty"` // OTLP/HTTP collector host and port, ex. localhost:4318
+	Insecure bool   `json:"insecure,omitempty"` // send spans to the OTLP collector over plain HTTP
+	FilePath string `json:"filePath,omitempty"` // file the spans are appended to by the file exporter
+}
+
+// WebhookConfig holds the configuration of the webhooks notified of publish failures
+type WebhookConfig struct {
+	URLs                 []string `json:"urls"`                 // webhooks every batch of failures is POSTed to, disabled if empty
+	Template             string   `json:"template,omitempty"`   // text/template of the JSON payload, rendered with the batch of failures
+	BatchSize            int      `json:"batchSize"`            // max number of failures sent in one payload
+	BatchIntervalSeconds int      `json:"batchIntervalSeconds"` // how long failures are collected before being sent
+	MaxRetries           int      `json:"maxRetries"`           // how many times a payload is resent to a failing webhook
+	DedupWindowMinutes   int      `json:"dedupWindowMinutes"`   // further failures for the same UUID within this window are not sent
+}
+
+// GraphiteConfig holds the configuration of the connections to the Graphite at graphiteAddress
+type GraphiteConfig struct {
+	Protocol             string `json:"protocol,omitempty"`      // tcp (default) or udp for the plaintext protocol, or pickle
+	Connections          int    `json:"connections"`             // size of the connection pool, defaults to 1
+	BufferSize           int    `json:"bufferSize"`              // metrics kept in memory while Graphite is unreachable, defaults to 10000
+	SpoolFilePath        string `json:"spoolFilePath,omitempty"` // file the metrics overflowing the buffer are written to, they are dropped if empty
+	SpoolMaxMetrics      int    `json:"spoolMaxMetrics"`         // metrics kept in the spool file, defaults to 100000
+	BatchSize            int    `json:"batchSize"`               // metrics written at once, defaults to 500
+	FlushIntervalSeconds int    `json:"flushIntervalSeconds"`    // how often the buffer is flushed, defaults to 1
+}
+
+// StatsConfig holds the configuration of the rolling stats of the regular publishes
+type StatsConfig struct {
+	Sink                  string `json:"sink,omitempty"`          // graphite or statsd, disabled if empty
+	StatsDAddress         string `json:"statsdAddress,omitempty"` // StatsD host and port, ex. localhost:8125
+	Prefix                string `json:"prefix,omitempty"`        // first node of the gauge paths, defaults to publishes
+	WindowMinutes         int    `json:"windowMinutes"`           // the stats cover the publishes in this window, defaults to 15
+	ReportIntervalSeconds int    `json:"reportIntervalSeconds"`   // how often the stats are reported, defaults to 60
+}
+
+// SLAConfig holds the configuration of the SLA compliance reported at /__sla
+type SLAConfig struct {
+	Windows []string `json:"windows"` // rolling windows, ex. 1h or 7d, defaults to 1h, 24h, 7d and 30d
 }
 
 // Capability represents business capability configuration
@@ -87,6 +231,67 @@ func (cfg *AppConfig) GetCapability(metricAlias string) *Capability {
 	return nil
 }
 
+// GetThreshold returns the publish SLA in seconds of content of contentType at the metric endpoint.
+// The threshold of the metric takes precedence over the one of the content type, which takes precedence over the global one.
+func (cfg *AppConfig) GetThreshold(metric MetricConfig, contentType string) int {
+	if metric.Threshold > 0 {
+		return metric.Threshold
+	}
+	if threshold := cfg.ContentTypeThresholds[contentType]; threshold > 0 {
+		return threshold
+	}
+	return cfg.Threshold
+}
+
+// GetThresholdRange returns the shortest and the longest publish SLA of the content types checked at the metric endpoint,
+// or its SLA for any content type if it lists none.
+func (cfg *AppConfig) GetThresholdRange(metric MetricConfig) (int, int) {
+	if len(metric.ContentTypes) == 0 {
+		threshold := cfg.GetThreshold(metric, "")
+		return threshold, threshold
+	}
+
+	min := cfg.GetThreshold(metric, metric.ContentTypes[0])
+	max := min
+	for _, contentType := range metric.ContentTypes[1:] {
+		threshold := cfg.GetThreshold(metric, contentType)
+		if threshold < min {
+			min = threshold
+		}
+		if threshold > max {
+			max = threshold
+		}
+	}
+	return min, max
+}
+
+// GetNotificationsExpiry returns the seconds the notifications of the metric endpoint are kept for,
+// the longest publish SLA or, if late tracking carries the checks on after it, the hard limit.
+func (cfg *AppConfig) GetNotificationsExpiry(metric MetricConfig) int {
+	_, max := cfg.GetThresholdRange(metric)
+	if cfg.LateTrackingConf.HardLimitSeconds > max {
+		return cfg.LateTrackingConf.HardLimitSeconds
+	}
+	return max
+}
+
+// GetMaxThreshold returns the longest publish SLA of content of contentType across the endpoints checking it,
+// or its content type or global SLA if no endpoint checks it.
+func (cfg *AppConfig) GetMaxThreshold(contentType string) int {
+	max := 0
+	for _, metric := range cfg.MetricConf {
+		for _, ct := range metric.ContentTypes {
+			if ct == contentType && cfg.GetThreshold(metric, contentType) > max {
+				max = cfg.GetThreshold(metric, contentType)
+			}
+		}
+	}
+	if max == 0 {
+		return cfg.GetThreshold(MetricConfig{}, contentType)
+	}
+	return max
+}
+
 func IsE2ETestTransactionID(tid string, e2eTestUUIDs []string) bool {
 	for _, testUUID := range e2eTestUUIDs {
 		if strings.Contains(tid, testUUID) {
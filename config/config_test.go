@@ -0,0 +1,106 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	articleType = "application/vnd.ft-upp-article-internal+json"
+	videoType   = "application/vnd.ft-upp-video+json"
+	listType    = "application/vnd.ft-upp-list+json"
+)
+
+func newThresholdsConfig() *AppConfig {
+	return &AppConfig{
+		Threshold: 120,
+		ContentTypeThresholds: map[string]int{
+			videoType: 600,
+		},
+		MetricConf: []MetricConfig{
+			{Alias: "content", ContentTypes: []string{articleType, videoType}},
+			{Alias: "lists", ContentTypes: []string{listType}, Threshold: 60},
+			{Alias: "notifications-push", ContentTypes: []string{articleType}, Threshold: 300},
+		},
+	}
+}
+
+func TestGetThreshold(t *testing.T) {
+	cfg := newThresholdsConfig()
+
+	tests := map[string]struct {
+		Metric            MetricConfig
+		ContentType       string
+		ExpectedThreshold int
+	}{
+		"global threshold": {
+			Metric:            cfg.MetricConf[0],
+			ContentType:       articleType,
+			ExpectedThreshold: 120,
+		},
+		"content type threshold overrides global one": {
+			Metric:            cfg.MetricConf[0],
+			ContentType:       videoType,
+			ExpectedThreshold: 600,
+		},
+		"metric threshold overrides content type one": {
+			Metric:            MetricConfig{Threshold: 90},
+			ContentType:       videoType,
+			ExpectedThreshold: 90,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedThreshold, cfg.GetThreshold(test.Metric, test.ContentType))
+		})
+	}
+}
+
+func TestGetThresholdRange(t *testing.T) {
+	cfg := newThresholdsConfig()
+
+	min, max := cfg.GetThresholdRange(cfg.MetricConf[0])
+	assert.Equal(t, 120, min)
+	assert.Equal(t, 600, max)
+
+	min, max = cfg.GetThresholdRange(cfg.MetricConf[1])
+	assert.Equal(t, 60, min)
+	assert.Equal(t, 60, max)
+
+	min, max = cfg.GetThresholdRange(MetricConfig{Alias: "videos", ContentTypes: []string{videoType}})
+	assert.Equal(t, 600, min, "the global threshold should not apply to the content types which override it")
+	assert.Equal(t, 600, max)
+
+	min, max = cfg.GetThresholdRange(MetricConfig{Alias: "any"})
+	assert.Equal(t, 120, min)
+	assert.Equal(t, 120, max)
+}
+
+func TestGetCheckInterval(t *testing.T) {
+	assert.Equal(t, 10, MetricConfig{Granularity: 12}.GetCheckInterval(120))
+	assert.Equal(t, 1, MetricConfig{Granularity: 12}.GetCheckInterval(5), "the interval should be at least a second")
+	assert.Equal(t, 5, MetricConfig{}.GetCheckInterval(5))
+}
+
+func TestGetNotificationsExpiry(t *testing.T) {
+	cfg := newThresholdsConfig()
+	assert.Equal(t, 600, cfg.GetNotificationsExpiry(cfg.MetricConf[0]))
+
+	cfg.LateTrackingConf.HardLimitSeconds = 900
+	assert.Equal(t, 900, cfg.GetNotificationsExpiry(cfg.MetricConf[0]), "the notifications should be kept until the hard limit")
+	assert.Equal(t, 900, cfg.GetNotificationsExpiry(cfg.MetricConf[1]))
+
+	cfg.LateTrackingConf.HardLimitSeconds = 300
+	assert.Equal(t, 600, cfg.GetNotificationsExpiry(cfg.MetricConf[0]), "a hard limit below the SLA should not shorten the expiry")
+}
+
+func TestGetMaxThreshold(t *testing.T) {
+	cfg := newThresholdsConfig()
+
+	assert.Equal(t, 300, cfg.GetMaxThreshold(articleType))
+	assert.Equal(t, 600, cfg.GetMaxThreshold(videoType))
+	assert.Equal(t, 60, cfg.GetMaxThreshold(listType))
+	assert.Equal(t, 120, cfg.GetMaxThreshold("application/unknown"))
+}
@@ -2,17 +2,24 @@ package content
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/Financial-Times/publish-availability-monitor/httpcaller"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+var tracer = otel.Tracer("github.com/Financial-Times/publish-availability-monitor/content")
+
 // Content is the interface for different type of contents from different CMSs.
 type Content interface {
 	Initialize(binaryContent []byte) Content
 	Validate(
+		ctx context.Context,
 		externalValidationEndpoint, tid, username, password string,
 		log *logger.UPPLogger,
 	) ValidationResponse
@@ -45,11 +52,21 @@ func init() {
 }
 
 func doExternalValidation(
+	ctx context.Context,
 	p validationParam,
 	validCheck func(int) bool,
 	deletedCheck func(...int) bool,
 	log *logger.UPPLogger,
 ) ValidationResponse {
+	ctx, span := tracer.Start(ctx, "doExternalValidation")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("uuid", p.uuid),
+		attribute.String("transaction_id", p.tid),
+		attribute.String("content_type", p.contentType),
+		attribute.String("validation_url", p.validationURL),
+	)
+
 	logEntry := log.WithUUID(p.uuid).WithTransactionID(p.tid)
 
 	if p.validationURL == "" {
@@ -67,7 +84,7 @@ func doExternalValidation(
 		contentType = p.contentType
 	}
 
-	resp, err := httpCaller.DoCall(httpcaller.Config{
+	resp, err := httpCaller.DoCall(ctx, httpcaller.Config{
 		HTTPMethod:  "POST",
 		URL:         p.validationURL,
 		Username:    p.username,
@@ -77,6 +94,8 @@ func doExternalValidation(
 		Entity:      bytes.NewReader(p.binaryContent),
 	})
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		logEntry.WithError(err).
 			Warn("External validation for content failed while creating validation request. Skipping external validation.")
 		return ValidationResponse{true, deletedCheck()}
@@ -105,5 +124,11 @@ func doExternalValidation(
 		)
 	}
 
-	return ValidationResponse{validCheck(resp.StatusCode), deletedCheck(resp.StatusCode)}
+	valRes := ValidationResponse{validCheck(resp.StatusCode), deletedCheck(resp.StatusCode)}
+	span.SetAttributes(
+		attribute.Int("http.status_code", resp.StatusCode),
+		attribute.Bool("valid", valRes.IsValid),
+		attribute.Bool("marked_deleted", valRes.IsMarkedDeleted),
+	)
+	return valRes
 }
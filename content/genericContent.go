@@ -1,6 +1,7 @@
 package content
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Financial-Times/go-logger/v2"
@@ -22,6 +23,7 @@ func (gc GenericContent) Initialize(binaryContent []byte) Content {
 }
 
 func (gc GenericContent) Validate(
+	ctx context.Context,
 	externalValidationEndpoint, tid, username, password string,
 	log *logger.UPPLogger,
 ) ValidationResponse {
@@ -42,6 +44,7 @@ func (gc GenericContent) Validate(
 	}
 
 	return doExternalValidation(
+		ctx,
 		param,
 		gc.isValid,
 		gc.isMarkedDeleted,
@@ -1,6 +1,7 @@
 package content
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -113,7 +114,7 @@ func TestGenericContent_Validate(t *testing.T) {
 				}),
 			)
 
-			validationResponse := test.Content.Validate(
+			validationResponse := test.Content.Validate(context.Background(),
 				testServer.URL+"/validate",
 				tid,
 				"",
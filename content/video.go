@@ -1,6 +1,7 @@
 package content
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Financial-Times/go-logger/v2"
@@ -23,6 +24,7 @@ func (video Video) Initialize(binaryContent []byte) Content {
 }
 
 func (video Video) Validate(
+	ctx context.Context,
 	externalValidationEndpoint, tid, username, password string,
 	log *logger.UPPLogger,
 ) ValidationResponse {
@@ -44,6 +46,7 @@ func (video Video) Validate(
 	}
 
 	return doExternalValidation(
+		ctx,
 		param,
 		video.isValid,
 		video.isMarkedDeleted,
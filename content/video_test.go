@@ -1,6 +1,7 @@
 package content
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -34,7 +35,7 @@ func TestIsVideoValid_Valid(t *testing.T) {
 
 	log := logger.NewUPPLogger("test", "PANIC")
 
-	validationResponse := videoValid.Validate(testServer.URL+"/map", tid, "", "", log)
+	validationResponse := videoValid.Validate(context.Background(), testServer.URL+"/map", tid, "", "", log)
 	assert.True(t, validationResponse.IsValid, "Video should be valid.")
 }
 
@@ -42,7 +43,7 @@ func TestIsVideoValid_NoId(t *testing.T) {
 	videoNoID := Video{}
 	log := logger.NewUPPLogger("test", "PANIC")
 
-	validationResponse := videoNoID.Validate("", "", "", "", log)
+	validationResponse := videoNoID.Validate(context.Background(), "", "", "", "", log)
 	assert.False(t, validationResponse.IsValid, "Video should be invalid as it has no Id.")
 }
 
@@ -70,7 +71,7 @@ func TestIsVideoValid_failedExternalValidation(t *testing.T) {
 	)
 
 	log := logger.NewUPPLogger("test", "PANIC")
-	validationResponse := videoInvalid.Validate(testServer.URL+"/map", tid, "", "", log)
+	validationResponse := videoInvalid.Validate(context.Background(), testServer.URL+"/map", tid, "", "", log)
 	assert.False(t, validationResponse.IsMarkedDeleted, "Video should fail external validation.")
 }
 
@@ -81,6 +82,6 @@ func TestIsDeleted(t *testing.T) {
 	}
 
 	log := logger.NewUPPLogger("test", "PANIC")
-	validationResponse := videoNoDates.Validate("", "", "", "", log)
+	validationResponse := videoNoDates.Validate(context.Background(), "", "", "", "", log)
 	assert.True(t, validationResponse.IsMarkedDeleted, "Video should be evaluated as deleted.")
 }
@@ -2,6 +2,7 @@ package envs
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
@@ -26,7 +27,11 @@ type Credentials struct {
 	Password string `json:"password"`
 }
 
+// WatchConfigFiles reloads the environments and the validation credentials when their files change,
+// and keeps the notifications feeds of the environments subscribed.
+// Once ctx is done, it stops watching and stops the feeds.
 func WatchConfigFiles(
+	ctx context.Context,
 	wg *sync.WaitGroup,
 	envsFileName, envCredentialsFileName, validationCredentialsFileName string,
 	configRefreshPeriod int,
@@ -37,12 +42,20 @@ func WatchConfigFiles(
 	log *logger.UPPLogger,
 ) {
 	ticker := newTicker(0, time.Minute*time.Duration(configRefreshPeriod))
+	defer ticker.Stop()
 	first := true
 	defer func() {
 		markWaitGroupDone(wg, first)
 	}()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			stopFeeds(subscribedFeeds)
+			return
+		case <-ticker.C:
+		}
+
 		err := updateEnvsIfChanged(envsFileName, envCredentialsFileName, configFilesHashValues, environments, subscribedFeeds, appConfig, log)
 		if err != nil {
 			log.WithError(err).Errorf("Could not update envs config")
@@ -57,6 +70,14 @@ func WatchConfigFiles(
 	}
 }
 
+func stopFeeds(subscribedFeeds map[string][]feeds.Feed) {
+	for _, envFeeds := range subscribedFeeds {
+		for _, f := range envFeeds {
+			f.Stop()
+		}
+	}
+}
+
 func markWaitGroupDone(wg *sync.WaitGroup, first bool) bool {
 	if first {
 		wg.Done()
@@ -249,9 +270,12 @@ func configureFileFeeds(envs []Environment, removedEnvs []string, subscribedFeed
 					continue
 				}
 
-				interval := appConfig.Threshold / metric.Granularity
+				// poll often enough for the shortest SLA and keep notifications for as long as they are checked
+				minThreshold, _ := appConfig.GetThresholdRange(metric)
+				interval := metric.GetCheckInterval(minThreshold)
+				expiry := appConfig.GetNotificationsExpiry(metric)
 
-				if f := feeds.NewNotificationsFeed(metric.Alias, *endpointURL, appConfig.Threshold, interval, env.Username, env.Password, metric.APIKey, log); f != nil {
+				if f := feeds.NewNotificationsFeed(metric.Alias, *endpointURL, expiry, interval, env.Username, env.Password, metric.APIKey, log); f != nil {
 					subscribedFeeds[env.Name] = append(envFeeds, f)
 					f.Start()
 				}
@@ -12,6 +12,7 @@ import (
 	"github.com/Financial-Times/publish-availability-monitor/config"
 	"github.com/Financial-Times/publish-availability-monitor/feeds"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -232,6 +233,21 @@ func TestConfigureFeedsWithEmptyListOfMetrics(t *testing.T) {
 	assert.Equal(t, 0, len(subscribedFeeds))
 }
 
+func TestConfigureFeedsWithGranularityFinerThanThreshold(t *testing.T) {
+	subscribedFeeds := map[string][]feeds.Feed{}
+	appConfig := &config.AppConfig{
+		Threshold:  120,
+		MetricConf: []config.MetricConfig{{Alias: "notifications", Endpoint: "/content/notifications", Granularity: 12, Threshold: 5}},
+	}
+	log := logger.NewUPPLogger("test", "PANIC")
+
+	assert.NotPanics(t, func() {
+		configureFileFeeds([]Environment{{Name: "test-env", ReadURL: "http://localhost"}}, nil, subscribedFeeds, appConfig, log)
+	})
+	require.Len(t, subscribedFeeds["test-env"], 1)
+	subscribedFeeds["test-env"][0].Stop()
+}
+
 func TestUpdateEnvsHappyFlow(t *testing.T) {
 	subscribedFeeds := map[string][]feeds.Feed{}
 	subscribedFeeds["test-feed"] = []feeds.Feed{
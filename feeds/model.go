@@ -1,10 +1,13 @@
 package feeds
 
+import "time"
+
 // ignore unused fields (e.g. type, apiUrl)
 type Notification struct {
 	PublishReference string
 	LastModified     string
 	ID               string
+	ReceivedAt       time.Time `json:"-"` // when the feed got the notification
 }
 
 // ignore unused field (e.g. rel)
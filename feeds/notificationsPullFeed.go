@@ -1,6 +1,7 @@
 package feeds
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"sync"
@@ -20,6 +21,7 @@ type NotificationsPullFeed struct {
 	interval                 int
 	ticker                   *time.Ticker
 	poller                   chan struct{}
+	cancel                   context.CancelFunc // cancels the calls in progress
 	log                      *logger.UPPLogger
 }
 
@@ -36,12 +38,14 @@ func (f *NotificationsPullFeed) Start() {
 
 	f.ticker = time.NewTicker(time.Duration(f.interval) * time.Second)
 	f.poller = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
 	go func() {
 		for {
 			select {
 			case <-f.ticker.C:
 				go func() {
-					f.pollNotificationsFeed()
+					f.pollNotificationsFeed(ctx)
 					f.purgeObsoleteNotifications()
 				}()
 			case <-f.poller:
@@ -55,13 +59,14 @@ func (f *NotificationsPullFeed) Start() {
 func (f *NotificationsPullFeed) Stop() {
 	f.log.Infof("shutting down notifications pull feed for %s", f.baseURL)
 	close(f.poller)
+	f.cancel()
 }
 
 func (f *NotificationsPullFeed) FeedType() string {
 	return NotificationsPull
 }
 
-func (f *NotificationsPullFeed) pollNotificationsFeed() {
+func (f *NotificationsPullFeed) pollNotificationsFeed(ctx context.Context) {
 	f.notificationsURLLock.Lock()
 	defer f.notificationsURLLock.Unlock()
 
@@ -69,7 +74,7 @@ func (f *NotificationsPullFeed) pollNotificationsFeed() {
 	log := f.log.WithTransactionID(tid)
 	notificationsURL := f.notificationsURL + "?" + f.notificationsQueryString
 
-	resp, err := f.httpCaller.DoCall(httpcaller.Config{
+	resp, err := f.httpCaller.DoCall(ctx, httpcaller.Config{
 		URL:      notificationsURL,
 		Username: f.username,
 		Password: f.password,
@@ -97,12 +102,14 @@ func (f *NotificationsPullFeed) pollNotificationsFeed() {
 		log.WithError(err).Error("Cannot decode json response")
 		return
 	}
+	receivedAt := time.Now()
 
 	f.notificationsLock.Lock()
 	defer f.notificationsLock.Unlock()
 
 	for _, v := range notifications.Notifications {
 		n := v
+		n.ReceivedAt = receivedAt
 		uuid := parseUUIDFromURL(n.ID)
 		var history []*Notification
 		var found bool
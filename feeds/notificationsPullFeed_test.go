@@ -2,6 +2,7 @@ package feeds
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -43,7 +44,7 @@ type testHTTPCaller struct {
 }
 
 // returns the mock responses of testHTTPCaller in order
-func (t *testHTTPCaller) DoCall(config httpcaller.Config) (*http.Response, error) {
+func (t *testHTTPCaller) DoCall(_ context.Context, config httpcaller.Config) (*http.Response, error) {
 	if t.authUser != config.Username || t.authPass != config.Password {
 		return buildResponse(401, `{message: "Not authenticated"}`, nil).response, nil
 	}
@@ -301,3 +302,21 @@ func TestNotificationsPollingFollowsOpaqueLink(t *testing.T) {
 	assert.Len(t, response2, 1, "notifications for "+uuid2)
 	assert.Equal(t, publishRef2, response2[0].PublishReference, "publish ref for "+uuid2)
 }
+
+func TestNotificationsAreKeptUntilExpiry(t *testing.T) {
+	sla, hardLimit := 120, 600
+	log := logger.NewUPPLogger("test", "PANIC")
+	baseURL, _ := url.Parse("http://www.example.org")
+
+	// late tracking keeps the notifications until the hard limit
+	f := NewNotificationsFeed("notifications", *baseURL, hardLimit, 1, "", "", "", log).(*NotificationsPullFeed)
+	late := time.Now().Add(-time.Duration(sla+60) * time.Second).UTC().Format(time.RFC3339)
+	lost := time.Now().Add(-time.Duration(hardLimit+60) * time.Second).UTC().Format(time.RFC3339)
+	f.notifications["late"] = []*Notification{{ID: "late", LastModified: late}}
+	f.notifications["lost"] = []*Notification{{ID: "lost", LastModified: lost}}
+
+	f.purgeObsoleteNotifications()
+
+	assert.Len(t, f.NotificationsFor("late"), 1, "a notification after the SLA but before the hard limit should be kept")
+	assert.Len(t, f.NotificationsFor("lost"), 0, "a notification after the hard limit should be purged")
+}
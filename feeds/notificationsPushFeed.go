@@ -2,6 +2,7 @@ package feeds
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"strings"
 	"sync"
@@ -17,6 +18,7 @@ type NotificationsPushFeed struct {
 	baseNotificationsFeed
 	stopFeed     bool
 	stopFeedLock *sync.RWMutex
+	cancel       context.CancelFunc // closes the connection to the feed
 	connected    bool
 	apiKey       string
 	log          *logger.UPPLogger
@@ -28,12 +30,14 @@ func (f *NotificationsPushFeed) Start() {
 	defer f.stopFeedLock.Unlock()
 
 	f.stopFeed = false
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
 	go func() {
 		if f.httpCaller == nil {
 			f.httpCaller = httpcaller.NewCaller(0)
 		}
 
-		for f.consumeFeed() {
+		for f.consumeFeed(ctx) {
 			time.Sleep(500 * time.Millisecond)
 			f.log.Info("Disconnected from Push feed! Attempting to reconnect.")
 		}
@@ -46,6 +50,9 @@ func (f *NotificationsPushFeed) Stop() {
 	defer f.stopFeedLock.Unlock()
 
 	f.stopFeed = true
+	if f.cancel != nil {
+		f.cancel()
+	}
 }
 
 func (f *NotificationsPushFeed) FeedType() string {
@@ -63,11 +70,11 @@ func (f *NotificationsPushFeed) isConsuming() bool {
 	return !f.stopFeed
 }
 
-func (f *NotificationsPushFeed) consumeFeed() bool {
+func (f *NotificationsPushFeed) consumeFeed(ctx context.Context) bool {
 	tid := f.buildNotificationsTID()
 	log := f.log.WithTransactionID(tid)
 
-	resp, err := f.httpCaller.DoCall(httpcaller.Config{
+	resp, err := f.httpCaller.DoCall(ctx, httpcaller.Config{
 		URL:      f.baseURL,
 		Username: f.username,
 		Password: f.password,
@@ -127,10 +134,13 @@ func (f *NotificationsPushFeed) consumeFeed() bool {
 }
 
 func (f *NotificationsPushFeed) storeNotifications(notifications []Notification) {
+	receivedAt := time.Now()
+
 	f.notificationsLock.Lock()
 	defer f.notificationsLock.Unlock()
 
 	for _, n := range notifications {
+		n.ReceivedAt = receivedAt
 		uuid := parseUUIDFromURL(n.ID)
 		var history []*Notification
 		var found bool
@@ -72,6 +72,7 @@ func TestPushNotificationsAreConsumed(t *testing.T) {
 	response := f.NotificationsFor(uuid)
 	assert.Len(t, response, 1, "notifications for item")
 	assert.Equal(t, publishRef, response[0].PublishReference, "publish ref")
+	assert.WithinDuration(t, time.Now(), response[0].ReceivedAt, time.Second, "receipt time")
 }
 
 func TestListPushNotificationsAreConsumed(t *testing.T) {
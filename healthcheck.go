@@ -16,6 +16,7 @@ import (
 	"github.com/Financial-Times/publish-availability-monitor/config"
 	"github.com/Financial-Times/publish-availability-monitor/envs"
 	"github.com/Financial-Times/publish-availability-monitor/feeds"
+	"github.com/Financial-Times/publish-availability-monitor/httpcaller"
 	"github.com/Financial-Times/publish-availability-monitor/metrics"
 	"github.com/Financial-Times/service-status-go/gtg"
 )
@@ -28,6 +29,8 @@ type Healthcheck struct {
 	config          *config.AppConfig
 	consumer        kafkaConsumer
 	metricContainer *metrics.History
+	slos            *metrics.SLOs
+	readAPIs        *httpcaller.HostProtection
 	environments    *envs.Environments
 	subscribedFeeds map[string][]feeds.Feed
 	log             *logger.UPPLogger
@@ -38,13 +41,15 @@ type kafkaConsumer interface {
 	MonitorCheck() error
 }
 
-func newHealthcheck(config *config.AppConfig, metricContainer *metrics.History, environments *envs.Environments, subscribedFeeds map[string][]feeds.Feed, c kafkaConsumer, log *logger.UPPLogger) *Healthcheck {
+func newHealthcheck(config *config.AppConfig, metricContainer *metrics.History, slos *metrics.SLOs, readAPIs *httpcaller.HostProtection, environments *envs.Environments, subscribedFeeds map[string][]feeds.Feed, c kafkaConsumer, log *logger.UPPLogger) *Healthcheck {
 	httpClient := &http.Client{Timeout: requestTimeout * time.Millisecond}
 	return &Healthcheck{
 		client:          httpClient,
 		config:          config,
 		consumer:        c,
 		metricContainer: metricContainer,
+		slos:            slos,
+		readAPIs:        readAPIs,
 		environments:    environments,
 		subscribedFeeds: subscribedFeeds,
 		log:             log,
@@ -79,6 +84,7 @@ func (h *Healthcheck) checkHealth() func(w http.ResponseWriter, r *http.Request)
 		h.validationServicesReachable(),
 		h.isConsumingFromPushFeeds(),
 		h.consumerMonitorCheck(),
+		h.readAPICircuitsClosed(),
 	}
 
 	readEnvironmentChecks := h.readEnvironmentsReachable()
@@ -88,17 +94,20 @@ func (h *Healthcheck) checkHealth() func(w http.ResponseWriter, r *http.Request)
 		checks = append(checks, readEnvironmentChecks...)
 	}
 
-	hc := fthealth.TimedHealthCheck{
-		HealthCheck: fthealth.HealthCheck{
-			SystemCode:  "publish-availability-monitor",
-			Name:        "Publish Availability Monitor",
-			Description: "Monitors publishes to the UPP platform and alerts on any publishing failures",
-			Checks:      checks,
-		},
-		Timeout: 10 * time.Second,
-	}
+	// the SLO checks are built on every request as their severity depends on the current burn rate
+	return func(w http.ResponseWriter, r *http.Request) {
+		hc := fthealth.TimedHealthCheck{
+			HealthCheck: fthealth.HealthCheck{
+				SystemCode:  "publish-availability-monitor",
+				Name:        "Publish Availability Monitor",
+				Description: "Monitors publishes to the UPP platform and alerts on any publishing failures",
+				Checks:      append(checks[:len(checks):len(checks)], h.sloChecks()...),
+			},
+			Timeout: 10 * time.Second,
+		}
 
-	return fthealth.Handler(hc)
+		fthealth.Handler(hc)(w, r)
+	}
 }
 
 func (h *Healthcheck) GTG() gtg.Status {
@@ -180,6 +189,32 @@ func (h *Healthcheck) consumerMonitorCheck() fthealth.Check {
 	}
 }
 
+func (h *Healthcheck) readAPICircuitsClosed() fthealth.Check {
+	return fthealth.Check{
+		ID:               "ReadAPICircuitsClosed",
+		BusinessImpact:   "Publishes cannot be checked in some read environments. Their checks are inconclusive and excluded from the SLA measurement.",
+		Name:             "ReadAPICircuitsClosed",
+		PanicGuide:       pamRunbookURL,
+		Severity:         2,
+		TechnicalSummary: "The calls to some read API hosts kept failing, so they are not called until they had time to recover. Check the health of the delivery clusters.",
+		Checker:          h.checkReadAPICircuits,
+	}
+}
+
+func (h *Healthcheck) checkReadAPICircuits() (string, error) {
+	var open []string
+	for _, circuit := range h.readAPIs.Circuits() {
+		if circuit.State != httpcaller.CircuitClosed {
+			open = append(open, fmt.Sprintf("%s (%s since %s)", circuit.Host, circuit.State, circuit.OpenedAt.Format(time.RFC3339)))
+		}
+	}
+
+	if len(open) > 0 {
+		return "", fmt.Errorf("the circuits of %d read API hosts are not closed: %s", len(open), strings.Join(open, ", "))
+	}
+	return "All circuits closed", nil
+}
+
 func (h *Healthcheck) reflectPublishFailures() fthealth.Check {
 	return fthealth.Check{
 		ID:               "ReflectPublishFailures",
@@ -206,6 +241,48 @@ func (h *Healthcheck) checkForPublishFailures() (string, error) {
 	return "", nil
 }
 
+// sloChecks returns a check per SLO, failing when any burn-rate alert fires,
+// with the severity of the most severe one.
+func (h *Healthcheck) sloChecks() []fthealth.Check {
+	if h.slos == nil {
+		return nil
+	}
+
+	statuses := h.slos.Statuses()
+	hc := make([]fthealth.Check, 0, len(statuses))
+	for _, status := range statuses {
+		status := status
+		severity := uint8(3)
+		if status.Alert != nil {
+			severity = status.Alert.Severity
+		}
+
+		hc = append(hc, fthealth.Check{
+			ID:               "SLO-" + status.Name,
+			BusinessImpact:   fmt.Sprintf("Publishes are failing fast enough to exhaust the %s error budget of the %v%% publish SLO. This will reflect in the SLA measurement.", status.Window, status.Objective),
+			Name:             "SLO-" + status.Name,
+			PanicGuide:       pamRunbookURL,
+			Severity:         severity,
+			TechnicalSummary: "The error budget of the SLO is burning too fast over both a long and a short window. The severity increases with the burn rate.",
+			Checker: func() (string, error) {
+				return checkSLO(status)
+			},
+		})
+	}
+	return hc
+}
+
+func checkSLO(status metrics.SLOStatus) (string, error) {
+	summary := fmt.Sprintf("%d of %d publishes failed in the last %s, %.2f%% of the error budget remaining", status.Failed, status.Total, status.Window, status.BudgetRemaining)
+	if status.Alert == nil {
+		return summary, nil
+	}
+
+	alert := status.Alert
+	return summary, fmt.Errorf("the error budget is burning %.2f times faster than sustainable over %s and %.2f times over %s, above the %v threshold",
+		status.BurnRates[alert.Long], alert.Long, status.BurnRates[alert.Short], alert.Short, alert.Threshold)
+}
+
 func (h *Healthcheck) validationServicesReachable() fthealth.Check {
 	return fthealth.Check{
 		ID:               "validationServicesReachable",
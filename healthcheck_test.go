@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
 
 	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/httpcaller"
 	"github.com/Financial-Times/publish-availability-monitor/metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildFtHealthcheckUrl(t *testing.T) {
@@ -140,3 +145,60 @@ func TestPublishFailureForDistinctUUIDs(t *testing.T) {
 
 	assert.Error(t, err, "Expected Error for at least two distinct uuid publish fails")
 }
+
+func TestSLOChecks(t *testing.T) {
+	slos, err := metrics.NewSLOs([]config.SLOConfig{
+		{Name: "content", Objective: 99, Endpoint: "content"},
+		{Name: "lists", Objective: 99, Endpoint: "lists"},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		slos.Send(metrics.PublishMetric{
+			PublishOK:   i > 0,
+			PublishDate: time.Now(),
+			Config:      config.MetricConfig{Alias: "content"},
+		})
+		slos.Send(metrics.PublishMetric{
+			PublishOK:   true,
+			PublishDate: time.Now(),
+			Config:      config.MetricConfig{Alias: "lists"},
+		})
+	}
+
+	testHealthcheck := Healthcheck{slos: slos}
+	checks := testHealthcheck.sloChecks()
+	require.Len(t, checks, 2)
+
+	assert.Equal(t, "SLO-content", checks[0].ID)
+	assert.Equal(t, uint8(1), checks[0].Severity)
+	summary, err := checks[0].Checker()
+	assert.Error(t, err)
+	assert.Equal(t, "1 of 10 publishes failed in the last 30d, -900.00% of the error budget remaining", summary)
+
+	assert.Equal(t, "SLO-lists", checks[1].ID)
+	assert.Equal(t, uint8(3), checks[1].Severity)
+	_, err = checks[1].Checker()
+	assert.NoError(t, err)
+}
+
+func TestReadAPICircuitsCheck(t *testing.T) {
+	readAPIs := httpcaller.NewHostProtection(config.ReadAPIConfig{FailureThreshold: 1})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	testHealthcheck := Healthcheck{readAPIs: readAPIs}
+	summary, err := testHealthcheck.checkReadAPICircuits()
+	assert.NoError(t, err)
+	assert.Equal(t, "All circuits closed", summary)
+
+	resp, err := httpcaller.NewProtectedCaller(10, readAPIs).DoCall(context.Background(), httpcaller.Config{URL: server.URL})
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+
+	_, err = testHealthcheck.checkReadAPICircuits()
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+)
+
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 1000
+)
+
+// loadHistory serves the publish history as JSON, filtered by the query parameters
+// uuid, transaction_id, environment, endpoint, publishOK, from and to (RFC3339),
+// sorted by sort (publishDate or duration, prefixed by - for descending order)
+// and paginated by offset and limit.
+func loadHistory(metricContainer *metrics.History) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseHistoryQuery(r.URL.Query())
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, metricContainer.Query(q))
+	}
+}
+
+func parseHistoryQuery(params url.Values) (metrics.HistoryQuery, error) {
+	q := metrics.HistoryQuery{
+		UUID:          params.Get("uuid"),
+		TID:           params.Get("transaction_id"),
+		Environment:   params.Get("environment"),
+		EndpointAlias: params.Get("endpoint"),
+		Outcome:       metrics.Outcome(params.Get("outcome")),
+		SortBy:        metrics.HistorySortByPublishDate,
+		Limit:         defaultHistoryPageSize,
+	}
+
+	if v := params.Get("publishOK"); v != "" {
+		publishOK, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, fmt.Errorf("invalid publishOK [%s]", v)
+		}
+		q.PublishOK = &publishOK
+	}
+
+	var err error
+	if q.From, err = parseTimeParam(params, "from"); err != nil {
+		return q, err
+	}
+	if q.To, err = parseTimeParam(params, "to"); err != nil {
+		return q, err
+	}
+
+	if v := params.Get("sort"); v != "" {
+		q.Ascending = !strings.HasPrefix(v, "-")
+		q.SortBy = strings.TrimPrefix(v, "-")
+		if q.SortBy != metrics.HistorySortByPublishDate && q.SortBy != metrics.HistorySortByDuration {
+			return q, fmt.Errorf("invalid sort [%s]", v)
+		}
+	}
+
+	if q.Offset, err = parseIntParam(params, "offset", 0); err != nil {
+		return q, err
+	}
+	if q.Limit, err = parseIntParam(params, "limit", defaultHistoryPageSize); err != nil {
+		return q, err
+	}
+	if q.Limit == 0 || q.Limit > maxHistoryPageSize {
+		return q, fmt.Errorf("limit must be between 1 and %d", maxHistoryPageSize)
+	}
+
+	return q, nil
+}
+
+func parseTimeParam(params url.Values, name string) (time.Time, error) {
+	v := params.Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s [%s], expected RFC3339 date", name, v)
+	}
+	return t, nil
+}
+
+func parseIntParam(params url.Values, name string, defaultValue int) (int, error) {
+	v := params.Get(name)
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid %s [%s]", name, v)
+	}
+	return i, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"message": msg})
+}
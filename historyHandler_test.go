@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadHistory(t *testing.T) {
+	t0 := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	history := metrics.NewHistory([]metrics.PublishMetric{
+		{UUID: "1", TID: "tid_1", Platform: "eu", PublishOK: true, PublishDate: t0},
+		{UUID: "2", TID: "tid_2", Platform: "eu", PublishOK: false, PublishDate: t0.Add(time.Minute)},
+		{UUID: "3", TID: "tid_3", Platform: "us", PublishOK: false, PublishDate: t0.Add(2 * time.Minute)},
+	})
+
+	tests := map[string]struct {
+		Query          string
+		ExpectedStatus int
+		ExpectedTotal  int
+		ExpectedUUIDs  []string
+	}{
+		"all publishes newest first": {
+			Query:          "",
+			ExpectedStatus: http.StatusOK,
+			ExpectedTotal:  3,
+			ExpectedUUIDs:  []string{"3", "2", "1"},
+		},
+		"failed publishes in environment": {
+			Query:          "?publishOK=false&environment=eu",
+			ExpectedStatus: http.StatusOK,
+			ExpectedTotal:  1,
+			ExpectedUUIDs:  []string{"2"},
+		},
+		"time range sorted by publish date ascending": {
+			Query:          "?from=2023-10-01T12:01:00Z&sort=publishDate",
+			ExpectedStatus: http.StatusOK,
+			ExpectedTotal:  2,
+			ExpectedUUIDs:  []string{"2", "3"},
+		},
+		"pagination": {
+			Query:          "?limit=1&offset=1",
+			ExpectedStatus: http.StatusOK,
+			ExpectedTotal:  3,
+			ExpectedUUIDs:  []string{"2"},
+		},
+		"invalid publishOK": {
+			Query:          "?publishOK=maybe",
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		"invalid date": {
+			Query:          "?from=yesterday",
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		"invalid sort": {
+			Query:          "?sort=uuid",
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		"limit too large": {
+			Query:          "?limit=5000",
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/__history"+test.Query, nil)
+			w := httptest.NewRecorder()
+
+			loadHistory(history)(w, req)
+
+			require.Equal(t, test.ExpectedStatus, w.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				return
+			}
+
+			var page struct {
+				Total          int `json:"total"`
+				PublishMetrics []struct {
+					UUID string `json:"uuid"`
+				} `json:"publishMetrics"`
+			}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+
+			uuids := make([]string, 0)
+			for _, pm := range page.PublishMetrics {
+				uuids = append(uuids, pm.UUID)
+			}
+			assert.Equal(t, test.ExpectedTotal, page.Total)
+			assert.Equal(t, test.ExpectedUUIDs, uuids)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package httpcaller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+)
+
+const defaultCircuitOpenSeconds = 30
+
+// ErrCircuitOpen is returned for the calls to a host whose circuit is open, which are not made.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Circuit states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "halfOpen" // a trial call decides whether the circuit closes or opens again
+)
+
+// CircuitStatus describes the circuit breaker of a host.
+type CircuitStatus struct {
+	Host                string    `json:"host"`
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	OpenedAt            time.Time `json:"openedAt,omitempty"`
+}
+
+// HostProtection rate limits the calls to each host with a token bucket,
+// and stops calling the hosts which keep failing until they had time to recover.
+// A nil *HostProtection lets every call through.
+type HostProtection struct {
+	mu               sync.Mutex
+	hosts            map[string]*hostState
+	rate             float64
+	burst            float64
+	failureThreshold int
+	openFor          time.Duration
+	now              func() time.Time
+}
+
+type hostState struct {
+	// token bucket
+	tokens   float64
+	filledAt time.Time
+
+	// circuit breaker
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	trialInFlight       bool
+}
+
+// NewHostProtection returns the HostProtection configured by cfg.
+func NewHostProtection(cfg config.ReadAPIConfig) *HostProtection {
+	p := &HostProtection{
+		hosts:            make(map[string]*hostState),
+		rate:             cfg.RateLimit,
+		burst:            float64(cfg.Burst),
+		failureThreshold: cfg.FailureThreshold,
+		openFor:          time.Duration(cfg.OpenSeconds) * time.Second,
+		now:              time.Now,
+	}
+	if p.burst <= 0 {
+		p.burst = math.Max(1, math.Ceil(p.rate))
+	}
+	if p.openFor <= 0 {
+		p.openFor = defaultCircuitOpenSeconds * time.Second
+	}
+	return p
+}
+
+// acquire waits for the rate limit of host to allow a call, and returns ErrCircuitOpen if its circuit does not.
+// Calls which were allowed must be reported to release.
+func (p *HostProtection) acquire(ctx context.Context, host string) error {
+	if p == nil {
+		return nil
+	}
+
+	for {
+		wait := p.take(host)
+		if wait <= 0 {
+			break
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h := p.host(host)
+	switch h.state {
+	case CircuitOpen:
+		if p.now().Sub(h.openedAt) < p.openFor {
+			return fmt.Errorf("%w for %s", ErrCircuitOpen, host)
+		}
+		h.state = CircuitHalfOpen
+		h.trialInFlight = true
+	case CircuitHalfOpen:
+		if h.trialInFlight {
+			return fmt.Errorf("%w for %s", ErrCircuitOpen, host)
+		}
+		h.trialInFlight = true
+	}
+	return nil
+}
+
+// take takes a token from the bucket of host, or returns how long to wait for one.
+func (p *HostProtection) take(host string) time.Duration {
+	if p.rate <= 0 {
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h := p.host(host)
+	now := p.now()
+	h.tokens = math.Min(p.burst, h.tokens+now.Sub(h.filledAt).Seconds()*p.rate)
+	h.filledAt = now
+	if h.tokens >= 1 {
+		h.tokens--
+		return 0
+	}
+	return time.Duration((1 - h.tokens) / p.rate * float64(time.Second))
+}
+
+// release records whether the call to host failed, opening its circuit after too many consecutive failures.
+func (p *HostProtection) release(host string, failed bool) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h := p.host(host)
+	trial := h.state == CircuitHalfOpen
+	h.trialInFlight = false
+	if !failed {
+		h.state = CircuitClosed
+		h.consecutiveFailures = 0
+		return
+	}
+
+	h.consecutiveFailures++
+	if trial || (p.failureThreshold > 0 && h.consecutiveFailures >= p.failureThreshold) {
+		h.state = CircuitOpen
+		h.openedAt = p.now()
+	}
+}
+
+// abandon records that the call to host was cut short by its caller, which tells nothing of the health of the host:
+// the circuit is left as it was, but another trial call can be made if it was the trial one.
+func (p *HostProtection) abandon(host string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.host(host).trialInFlight = false
+}
+
+// host returns the state of host, created with a full bucket. Callers must hold the lock.
+func (p *HostProtection) host(host string) *hostState {
+	h, found := p.hosts[host]
+	if !found {
+		h = &hostState{tokens: p.burst, filledAt: p.now(), state: CircuitClosed}
+		p.hosts[host] = h
+	}
+	return h
+}
+
+// Circuits returns the circuit breakers of the hosts called so far, sorted by host.
+func (p *HostProtection) Circuits() []CircuitStatus {
+	if p == nil {
+		return []CircuitStatus{}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	circuits := make([]CircuitStatus, 0, len(p.hosts))
+	for host, h := range p.hosts {
+		status := CircuitStatus{
+			Host:                host,
+			State:               h.state,
+			ConsecutiveFailures: h.consecutiveFailures,
+		}
+		if h.state != CircuitClosed {
+			status.OpenedAt = h.openedAt
+		}
+		circuits = append(circuits, status)
+	}
+
+	sort.Slice(circuits, func(i, j int) bool {
+		return circuits[i].Host < circuits[j].Host
+	})
+	return circuits
+}
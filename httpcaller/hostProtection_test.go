@@ -0,0 +1,130 @@
+package httpcaller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostProtectionRateLimitsEachHost(t *testing.T) {
+	p := NewHostProtection(config.ReadAPIConfig{RateLimit: 2, Burst: 1})
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	assert.Zero(t, p.take("eu.example.org"))
+	assert.Equal(t, 500*time.Millisecond, p.take("eu.example.org"))
+	assert.Zero(t, p.take("us.example.org"), "hosts should have their own bucket")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.Zero(t, p.take("eu.example.org"))
+}
+
+func TestHostProtectionAcquireWaitsForToken(t *testing.T) {
+	p := NewHostProtection(config.ReadAPIConfig{RateLimit: 20, Burst: 1})
+
+	start := time.Now()
+	require.NoError(t, p.acquire(context.Background(), "eu.example.org"))
+	require.NoError(t, p.acquire(context.Background(), "eu.example.org"))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, p.acquire(ctx, "eu.example.org"), context.Canceled)
+}
+
+func TestHostProtectionCircuitBreaker(t *testing.T) {
+	p := NewHostProtection(config.ReadAPIConfig{FailureThreshold: 2, OpenSeconds: 10})
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	host := "eu.example.org"
+
+	require.NoError(t, p.acquire(context.Background(), host))
+	p.release(host, true)
+	require.NoError(t, p.acquire(context.Background(), host))
+	p.release(host, true)
+
+	assert.ErrorIs(t, p.acquire(context.Background(), host), ErrCircuitOpen)
+	assert.Equal(t, []CircuitStatus{{Host: host, State: CircuitOpen, ConsecutiveFailures: 2, OpenedAt: now}}, p.Circuits())
+
+	// a single trial call is let through once the circuit was open long enough
+	now = now.Add(10 * time.Second)
+	require.NoError(t, p.acquire(context.Background(), host))
+	assert.ErrorIs(t, p.acquire(context.Background(), host), ErrCircuitOpen)
+	assert.Equal(t, CircuitHalfOpen, p.Circuits()[0].State)
+
+	// the circuit opens again if the trial call fails
+	p.release(host, true)
+	assert.ErrorIs(t, p.acquire(context.Background(), host), ErrCircuitOpen)
+
+	now = now.Add(10 * time.Second)
+	require.NoError(t, p.acquire(context.Background(), host))
+	p.release(host, false)
+	assert.Equal(t, []CircuitStatus{{Host: host, State: CircuitClosed}}, p.Circuits())
+	assert.NoError(t, p.acquire(context.Background(), host))
+}
+
+func TestHostProtectionAbandonedTrial(t *testing.T) {
+	p := NewHostProtection(config.ReadAPIConfig{FailureThreshold: 1, OpenSeconds: 10})
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	host := "eu.example.org"
+
+	require.NoError(t, p.acquire(context.Background(), host))
+	p.release(host, true)
+	now = now.Add(10 * time.Second)
+	require.NoError(t, p.acquire(context.Background(), host))
+
+	p.abandon(host)
+	assert.Equal(t, CircuitHalfOpen, p.Circuits()[0].State, "an abandoned trial call should neither open nor close the circuit")
+	assert.NoError(t, p.acquire(context.Background(), host), "another trial call should be let through")
+}
+
+func TestProtectedCallerIgnoresCallsCancelledByCaller(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	protection := NewHostProtection(config.ReadAPIConfig{FailureThreshold: 1})
+	httpCaller := NewProtectedCaller(10, protection)
+
+	for _, ctxErr := range []error{context.Canceled, context.DeadlineExceeded} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		if ctxErr == context.Canceled {
+			time.AfterFunc(10*time.Millisecond, cancel)
+		}
+		_, err := httpCaller.DoCall(ctx, Config{URL: server.URL}) //nolint:bodyclose
+		cancel()
+		assert.ErrorIs(t, err, ctxErr)
+	}
+
+	require.Len(t, protection.Circuits(), 1)
+	assert.Equal(t, CircuitClosed, protection.Circuits()[0].State, "calls cut short by the caller should not count as failures")
+	assert.Zero(t, protection.Circuits()[0].ConsecutiveFailures)
+}
+
+func TestProtectedCallerStopsCallingFailingHost(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	httpCaller := NewProtectedCaller(10, NewHostProtection(config.ReadAPIConfig{FailureThreshold: 2}))
+	resp, err := httpCaller.DoCall(context.Background(), Config{URL: server.URL})
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, 2, calls)
+
+	resp, err = httpCaller.DoCall(context.Background(), Config{URL: server.URL}) //nolint:bodyclose
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Nil(t, resp)
+	assert.Equal(t, 2, calls, "calls should not be made while the circuit is open")
+}
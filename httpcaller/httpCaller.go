@@ -1,6 +1,8 @@
 package httpcaller
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,16 +10,24 @@ import (
 	"time"
 
 	"github.com/giantswarm/retry-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/Financial-Times/publish-availability-monitor/httpcaller")
+
 // Caller abstracts http calls
 type Caller interface {
-	DoCall(config Config) (*http.Response, error)
+	DoCall(ctx context.Context, config Config) (*http.Response, error)
 }
 
 // Default implementation of Caller
 type DefaultCaller struct {
-	client *http.Client
+	client     *http.Client
+	protection *HostProtection
 }
 
 type Config struct {
@@ -49,15 +59,27 @@ func NewCaller(timeoutSeconds int) DefaultCaller {
 		}
 	}
 
-	return DefaultCaller{&client}
+	return DefaultCaller{client: &client}
+}
+
+// NewProtectedCaller returns a Caller whose calls are rate limited and stopped by the circuit breakers of protection.
+func NewProtectedCaller(timeoutSeconds int, protection *HostProtection) DefaultCaller {
+	c := NewCaller(timeoutSeconds)
+	c.protection = protection
+	return c
 }
 
-// Performs http GET calls using the default http client
-func (c DefaultCaller) DoCall(config Config) (resp *http.Response, err error) {
+// Performs http GET calls using the default http client.
+// Every attempt is traced in its own span, child of the span in ctx.
+// An error wrapping ErrCircuitOpen is returned if the circuit of the host is open.
+func (c DefaultCaller) DoCall(ctx context.Context, config Config) (resp *http.Response, err error) {
 	if config.HTTPMethod == "" {
 		config.HTTPMethod = "GET"
 	}
-	req, err := http.NewRequest(config.HTTPMethod, config.URL, config.Entity)
+	req, err := http.NewRequestWithContext(ctx, config.HTTPMethod, config.URL, config.Entity)
+	if err != nil {
+		return nil, err
+	}
 	if config.Username != "" && config.Password != "" {
 		req.SetBasicAuth(config.Username, config.Password)
 	}
@@ -80,14 +102,50 @@ func (c DefaultCaller) DoCall(config Config) (resp *http.Response, err error) {
 
 	req.Header.Add("User-Agent", "UPP Publish Availability Monitor")
 
+	attempt := 0
 	op := func() error {
-		resp, err = c.client.Do(req) //nolint:bodyclose
+		attempt++
+		spanCtx, span := tracer.Start(ctx, "httpcaller.DoCall",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("http.method", config.HTTPMethod),
+				attribute.String("http.url", config.URL),
+				attribute.String("transaction_id", config.TID),
+				attribute.Int("attempt", attempt),
+			),
+		)
+		defer span.End()
+
+		if protectionErr := c.protection.acquire(spanCtx, req.URL.Host); protectionErr != nil {
+			if resp != nil {
+				_ = resp.Body.Close()
+			}
+			resp, err = nil, protectionErr
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+
+		attemptReq := req.WithContext(spanCtx)
+		attemptReq.Header = req.Header.Clone()
+		otel.GetTextMapPropagator().Inject(spanCtx, propagation.HeaderCarrier(attemptReq.Header))
+
+		resp, err = c.client.Do(attemptReq) //nolint:bodyclose
+		if err != nil && ctx.Err() != nil {
+			c.protection.abandon(req.URL.Host)
+		} else {
+			c.protection.release(req.URL.Host, err != nil || resp.StatusCode >= 500 && resp.StatusCode < 600)
+		}
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 			return err
 		}
 
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
 		if resp.StatusCode >= 500 && resp.StatusCode < 600 {
 			// Error status code: create an err in order to trigger a retry
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
 			return fmt.Errorf("error status code received: %d", resp.StatusCode)
 		}
 		return nil
@@ -95,7 +153,7 @@ func (c DefaultCaller) DoCall(config Config) (resp *http.Response, err error) {
 
 	_ = retry.Do(
 		op,
-		retry.RetryChecker(func(err error) bool { return err != nil }),
+		retry.RetryChecker(func(err error) bool { return err != nil && !errors.Is(err, ErrCircuitOpen) }),
 		retry.MaxTries(2),
 	)
 	return resp, err
@@ -1,6 +1,7 @@
 package httpcaller
 
 import (
+	"context"
 	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
@@ -8,6 +9,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func stubServer(t *testing.T, expectedMethod string, expectedHeaders map[string]string, expectedBody []byte) *httptest.Server {
@@ -49,7 +56,7 @@ func TestUnauthenticated(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewCaller(10)
-	resp, err := httpCaller.DoCall(Config{URL: server.URL})
+	resp, err := httpCaller.DoCall(context.Background(), Config{URL: server.URL})
 	assert.Nil(t, err, "unexpected error")
 
 	assertExpectedResponse(t, resp)
@@ -68,7 +75,7 @@ func TestAuthenticated(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewCaller(10)
-	resp, err := httpCaller.DoCall(Config{URL: server.URL, Username: username, Password: password, APIKey: apiKey})
+	resp, err := httpCaller.DoCall(context.Background(), Config{URL: server.URL, Username: username, Password: password, APIKey: apiKey})
 	assert.Nil(t, err, "unexpected error")
 
 	assertExpectedResponse(t, resp)
@@ -84,7 +91,7 @@ func TestTransactionId(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewCaller(10)
-	resp, err := httpCaller.DoCall(Config{URL: server.URL, TID: tid})
+	resp, err := httpCaller.DoCall(context.Background(), Config{URL: server.URL, TID: tid})
 	assert.Nil(t, err, "unexpected error")
 
 	assertExpectedResponse(t, resp)
@@ -104,7 +111,7 @@ func TestRequestWithEntity(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewCaller(10)
-	resp, err := httpCaller.DoCall(Config{HTTPMethod: "POST", URL: server.URL, ContentType: contentType, Entity: strings.NewReader(body)})
+	resp, err := httpCaller.DoCall(context.Background(), Config{HTTPMethod: "POST", URL: server.URL, ContentType: contentType, Entity: strings.NewReader(body)})
 	assert.Nil(t, err, "unexpected error")
 
 	assertExpectedResponse(t, resp)
@@ -123,7 +130,7 @@ func TestClientDoesRetry(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewCaller(10)
-	_, err := httpCaller.DoCall(Config{HTTPMethod: "GET", URL: server.URL}) //nolint:bodyclose
+	_, err := httpCaller.DoCall(context.Background(), Config{HTTPMethod: "GET", URL: server.URL}) //nolint:bodyclose
 	assert.NoError(t, err)
 	assert.Equal(t, 2, retryCount)
 }
@@ -137,7 +144,7 @@ func TestClientDoesOnlyTwoRetries(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewCaller(10)
-	_, err := httpCaller.DoCall(Config{HTTPMethod: "GET", URL: server.URL}) //nolint:bodyclose
+	_, err := httpCaller.DoCall(context.Background(), Config{HTTPMethod: "GET", URL: server.URL}) //nolint:bodyclose
 	assert.NoError(t, err)
 	assert.Equal(t, 2, retryCount)
 }
@@ -151,7 +158,48 @@ func TestClientDoesNoRetryAfterSuccessfullResponse(t *testing.T) {
 	defer server.Close()
 
 	httpCaller := NewCaller(10)
-	_, err := httpCaller.DoCall(Config{HTTPMethod: "GET", URL: server.URL}) //nolint:bodyclose
+	_, err := httpCaller.DoCall(context.Background(), Config{HTTPMethod: "GET", URL: server.URL}) //nolint:bodyclose
 	assert.NoError(t, err)
 	assert.Equal(t, 1, retryCount)
 }
+
+func TestEveryAttemptIsTraced(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceParents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents = append(traceParents, r.Header.Get("traceparent"))
+		if len(traceParents) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	httpCaller := NewCaller(10)
+	_, err := httpCaller.DoCall(ctx, Config{URL: server.URL, TID: "tid_test"}) //nolint:bodyclose
+	require.NoError(t, err)
+	parent.End()
+
+	var attempts []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "httpcaller.DoCall" {
+			attempts = append(attempts, span)
+		}
+	}
+	require.Len(t, attempts, 2)
+	require.Len(t, traceParents, 2)
+
+	for i, span := range attempts {
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID(), "attempt should be part of the caller's trace")
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Contains(t, span.Attributes(), attribute.Int("attempt", i+1))
+		assert.Contains(t, traceParents[i], span.SpanContext().SpanID().String(), "trace context should be propagated downstream")
+	}
+	assert.Contains(t, attempts[0].Attributes(), attribute.Int("http.status_code", http.StatusServiceUnavailable))
+	assert.Contains(t, attempts[1].Attributes(), attribute.Int("http.status_code", http.StatusOK))
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/gorilla/mux"
+)
+
+// listInFlightChecks serves the checks currently polling endpoints, optionally filtered by the uuid query parameter.
+func listInFlightChecks(inFlight *checks.InFlightChecks) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uuid := r.URL.Query().Get("uuid")
+
+		list := make([]checks.InFlightCheck, 0)
+		for _, c := range inFlight.List() {
+			if uuid == "" || c.UUID == uuid {
+				list = append(list, c)
+			}
+		}
+
+		writeJSON(w, http.StatusOK, list)
+	}
+}
+
+// cancelInFlightCheck stops the check with the given ID.
+func cancelInFlightCheck(inFlight *checks.InFlightChecks) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+
+		if !inFlight.Cancel(id) {
+			writeJSONError(w, http.StatusNotFound, "no check in flight with ID "+id)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// cancelInFlightChecksForUUID stops every check of the content given by the uuid query parameter.
+func cancelInFlightChecksForUUID(inFlight *checks.InFlightChecks) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uuid := r.URL.Query().Get("uuid")
+		if uuid == "" {
+			writeJSONError(w, http.StatusBadRequest, "uuid query parameter is required")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]int{"cancelled": inFlight.CancelUUID(uuid)})
+	}
+}
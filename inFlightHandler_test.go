@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInFlightHandlers(t *testing.T) {
+	inFlight := checks.NewInFlightChecks()
+	router := mux.NewRouter()
+	router.HandleFunc("/__inflight", listInFlightChecks(inFlight)).Methods(http.MethodGet)
+	router.HandleFunc("/__inflight", cancelInFlightChecksForUUID(inFlight)).Methods(http.MethodDelete)
+	router.HandleFunc("/__inflight/{id}", cancelInFlightCheck(inFlight)).Methods(http.MethodDelete)
+
+	tests := map[string]struct {
+		Method         string
+		Path           string
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		"list with no checks in flight": {
+			Method:         http.MethodGet,
+			Path:           "/__inflight",
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   "[]\n",
+		},
+		"cancel unknown check": {
+			Method:         http.MethodDelete,
+			Path:           "/__inflight/unknown",
+			ExpectedStatus: http.StatusNotFound,
+		},
+		"cancel checks for uuid": {
+			Method:         http.MethodDelete,
+			Path:           "/__inflight?uuid=077f5ac2-0491-420e-a5d0-982e0f86204b",
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   "{\"cancelled\":0}\n",
+		},
+		"cancel checks without uuid": {
+			Method:         http.MethodDelete,
+			Path:           "/__inflight",
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(test.Method, test.Path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.ExpectedStatus, w.Code)
+			if test.ExpectedBody != "" {
+				assert.Equal(t, test.ExpectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package jsonl reads and writes files of JSON encoded values, one per line.
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// maxLineSize is the size of the longest line which can be read.
+const maxLineSize = 1024 * 1024
+
+// ReadFile returns the values of the file at path, or none if the file is missing.
+// Lines which cannot be decoded, e.g. one left partially written by a crash, are skipped.
+func ReadFile[T any](path string) ([]T, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []T{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open file [%s]: %w", path, err)
+	}
+	defer file.Close()
+
+	values := make([]T, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var value T
+		if err = json.Unmarshal(scanner.Bytes(), &value); err != nil {
+			continue
+		}
+		values = append(values, value)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read file [%s]: %w", path, err)
+	}
+	return values, nil
+}
+
+// WriteFile atomically replaces the contents of the file at path with values:
+// they are written to a temporary file next to it, which is synced then renamed over it.
+func WriteFile[T any](path string, values []T) error {
+	tmpPath := path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot create file [%s]: %w", tmpPath, err)
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, value := range values {
+		if err = enc.Encode(value); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err = w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("cannot replace file [%s]: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package jsonl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type value struct {
+	Name string `json:"name"`
+}
+
+func TestWriteFileThenReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.jsonl")
+	values := []value{{Name: "a"}, {Name: "b"}}
+
+	require.NoError(t, WriteFile(path, values))
+	read, err := ReadFile[value](path)
+	require.NoError(t, err)
+	assert.Equal(t, values, read)
+
+	require.NoError(t, WriteFile(path, values[1:]))
+	read, err = ReadFile[value](path)
+	require.NoError(t, err)
+	assert.Equal(t, values[1:], read, "the file contents should be replaced")
+
+	_, err = os.Stat(path + ".tmp")
+	assert.ErrorIs(t, err, os.ErrNotExist, "the temporary file should be renamed")
+}
+
+func TestReadFileMissing(t *testing.T) {
+	read, err := ReadFile[value](filepath.Join(t.TempDir(), "missing.jsonl"))
+	require.NoError(t, err)
+	assert.Empty(t, read)
+}
+
+func TestReadFileSkipsUndecodableLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"name\": \"a\"}\nnot json\n{\"name\": \"b\"}\n{\"na"), 0o644))
+
+	read, err := ReadFile[value](path)
+	require.NoError(t, err)
+	assert.Equal(t, []value{{Name: "a"}, {Name: "b"}}, read)
+}
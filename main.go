@@ -1,10 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
-	"fmt"
 	"net/http"
-	"os"
 	"os/signal"
 	"regexp"
 	"sync"
@@ -13,9 +13,11 @@ import (
 
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/Financial-Times/kafka-client-go/v4"
+	"github.com/Financial-Times/publish-availability-monitor/checks"
 	"github.com/Financial-Times/publish-availability-monitor/config"
 	"github.com/Financial-Times/publish-availability-monitor/envs"
 	"github.com/Financial-Times/publish-availability-monitor/feeds"
+	"github.com/Financial-Times/publish-availability-monitor/httpcaller"
 	"github.com/Financial-Times/publish-availability-monitor/metrics"
 	status "github.com/Financial-Times/service-status-go/httphandlers"
 	"github.com/gorilla/mux"
@@ -47,6 +49,34 @@ var configRefreshPeriod = flag.Int(
 	"Refresh period for configuration in minutes. By default it is 1 minute.",
 )
 
+var replayFileName = flag.String(
+	"replay",
+	"",
+	"Path to a JSONL file of recorded messages to replay instead of consuming Kafka",
+)
+
+var replayTimestamps = flag.String(
+	"replay-timestamps",
+	replayTimestampsShifted,
+	"Message-Timestamp of the replayed messages, shifted to now or original",
+)
+
+var replayReportFileName = flag.String(
+	"replay-report",
+	"",
+	"Path to the JSON report of the replay. By default it is written to the standard output.",
+)
+
+const splunkFormatJSON = "json"
+
+const (
+	statsSinkGraphite = "graphite"
+	statsSinkStatsD   = "statsd"
+)
+
+// maxPublishEvents is the number of most recent publishes whose checks are available at /__publishes/{tid}
+const maxPublishEvents = 1000
+
 var carouselTransactionIDRegExp = regexp.MustCompile(`^.+_carousel_[\d]{10}.*$`)
 
 func main() {
@@ -61,6 +91,24 @@ func main() {
 		return
 	}
 
+	shutdownTracing, err := setupTracing(appConfig.TracingConf)
+	if err != nil {
+		log.WithError(err).Error("Cannot set up tracing")
+		return
+	}
+	defer func() {
+		if err = shutdownTracing(context.Background()); err != nil {
+			log.WithError(err).Error("Error flushing traces")
+		}
+	}()
+
+	if *replayFileName != "" {
+		if err = replay(appConfig, *replayFileName, *replayTimestamps, *replayReportFileName, log); err != nil {
+			log.WithError(err).Error("Cannot replay messages")
+		}
+		return
+	}
+
 	environments := envs.NewEnvironments()
 	subscribedFeeds := make(map[string][]feeds.Feed)
 	metricSink := make(chan metrics.PublishMetric)
@@ -71,30 +119,51 @@ func main() {
 
 	log.Info("Sourcing dynamic configs from file")
 
-	go envs.WatchConfigFiles(
-		wg,
-		*envsFileName,
-		*envCredentialsFileName,
-		*validatorCredentialsFileName,
-		*configRefreshPeriod,
-		configFilesHashValues,
-		environments,
-		subscribedFeeds,
-		appConfig,
-		log,
-	)
+	// the feeds are stopped with the watch, once the checks are over
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		envs.WatchConfigFiles(
+			watchCtx,
+			wg,
+			*envsFileName,
+			*envCredentialsFileName,
+			*validatorCredentialsFileName,
+			*configRefreshPeriod,
+			configFilesHashValues,
+			environments,
+			subscribedFeeds,
+			appConfig,
+			log,
+		)
+	}()
 
 	wg.Wait()
 
-	metricContainer := metrics.NewHistory(make([]metrics.PublishMetric, 0))
-
-	var e2eTestUUIDs []string
-	for _, c := range appConfig.Capabilities {
-		for _, id := range c.TestIDs {
-			if !sliceContains(e2eTestUUIDs, id) {
-				e2eTestUUIDs = append(e2eTestUUIDs, id)
-			}
+	metricContainer := newMetricContainer(appConfig.HistoryConf, log)
+	defer func() {
+		if err = metricContainer.Close(); err != nil {
+			log.WithError(err).Error("Error closing publish history")
 		}
+	}()
+
+	publishEvents := checks.NewPublishEvents(maxPublishEvents)
+	inFlight := checks.NewInFlightChecks()
+	scheduler := checks.NewCheckScheduler(appConfig.SchedulerConf, log)
+	// read before the scheduler replaces the checkpoint with its own checks
+	checkpointed, err := scheduler.Checkpointed()
+	if err != nil {
+		log.WithError(err).Error("Cannot read the checkpointed checks, they will not be resumed")
+	}
+	go scheduler.Run()
+	readAPIProtection := httpcaller.NewHostProtection(appConfig.ReadAPIConf)
+
+	recorder, err := newMessageRecorder(appConfig.RecorderConf)
+	if err != nil {
+		log.WithError(err).Error("Cannot set up the recording of consumed messages")
+		return
 	}
 
 	var arn *string
@@ -106,9 +175,18 @@ func main() {
 		appConfig,
 		environments,
 		subscribedFeeds,
-		metricSink,
-		metricContainer,
-		e2eTestUUIDs,
+		MessageHandlerDeps{
+			SchedulerDeps: checks.SchedulerDeps{
+				MetricSink:    metricSink,
+				History:       metricContainer,
+				PublishEvents: publishEvents,
+				InFlight:      inFlight,
+				Scheduler:     scheduler,
+			},
+			HTTPCaller: httpcaller.NewProtectedCaller(10, readAPIProtection),
+			Recorder:   recorder,
+		},
+		e2eTestUUIDs(appConfig),
 		log,
 	)
 	consumer, err := kafka.NewConsumer(
@@ -130,14 +208,76 @@ func main() {
 		log.WithError(err).Fatal("Failed to create Kafka consumer")
 	}
 
-	go startHTTPServer(appConfig, environments, subscribedFeeds, metricContainer, consumer, log)
+	prometheusDestination := metrics.NewPrometheusDestination()
+	prometheusDestination.RegisterCheckQueue(metrics.CheckQueueStats{
+		Queued:  scheduler.Queued,
+		Due:     scheduler.Due,
+		Running: scheduler.Running,
+		Blocked: scheduler.Blocked,
+	})
+
+	sla, err := metrics.NewSLA(appConfig.SLAConf)
+	if err != nil {
+		log.WithError(err).Error("Cannot set up SLA compliance reporting")
+		return
+	}
+	slos, err := metrics.NewSLOs(appConfig.HealthConf.SLOs)
+	if err != nil {
+		log.WithError(err).Error("Cannot set up SLO healthchecks")
+		return
+	}
+	for _, pm := range metricContainer.Query(metrics.HistoryQuery{Ascending: true}).PublishMetrics {
+		sla.Send(pm)
+		slos.Send(pm)
+	}
+
+	server := startHTTPServer(appConfig, environments, subscribedFeeds, metricContainer, publishEvents, inFlight, messageHandler, prometheusDestination, sla, slos, readAPIProtection, consumer, log)
 
 	publishMetricDestinations := []metrics.Destination{
-		metrics.NewSplunkFeeder(appConfig.SplunkConf.LogPrefix),
+		newSplunkDestination(appConfig.SplunkConf, log),
+		prometheusDestination,
+		sla,
+		slos,
+	}
+
+	var webhookDestination *metrics.WebhookDestination
+	if len(appConfig.WebhookConf.URLs) > 0 {
+		webhookDestination, err = metrics.NewWebhookDestination(appConfig.WebhookConf, appConfig.Environment, log)
+		if err != nil {
+			log.WithError(err).Error("Cannot set up publish failure webhooks")
+			return
+		}
+		go webhookDestination.Run()
+		publishMetricDestinations = append(publishMetricDestinations, webhookDestination)
+	}
+
+	graphiteSender := metrics.NewGraphiteSender(appConfig, log)
+	prometheusDestination.RegisterDroppedMetrics("graphite", graphiteSender.Dropped)
+	go graphiteSender.Run()
+	defer func() {
+		if err = graphiteSender.Close(); err != nil {
+			log.WithError(err).Error("Error closing Graphite sender")
+		}
+	}()
+
+	switch appConfig.StatsConf.Sink {
+	case "":
+	case statsSinkGraphite, statsSinkStatsD:
+		var sink metrics.GaugeSink = graphiteSender
+		if appConfig.StatsConf.Sink == statsSinkStatsD {
+			sink = metrics.NewStatsDSender(appConfig.StatsConf.StatsDAddress, log)
+		}
+		publishStats := metrics.NewPublishStats(appConfig.StatsConf, sink)
+		go publishStats.Run()
+		defer publishStats.Stop()
+		publishMetricDestinations = append(publishMetricDestinations, publishStats)
+	default:
+		log.Errorf("Unknown publish stats sink [%s], publish stats are disabled", appConfig.StatsConf.Sink)
 	}
 
 	capabilityMetricDestinations := []metrics.Destination{
-		metrics.NewGraphiteSender(appConfig, log),
+		graphiteSender,
+		prometheusDestination,
 	}
 
 	aggregator := metrics.NewAggregator(
@@ -146,40 +286,118 @@ func main() {
 		capabilityMetricDestinations,
 		log,
 	)
-	go aggregator.Run()
+	aggregatorDone := make(chan struct{})
+	go func() {
+		defer close(aggregatorDone)
+		aggregator.Run()
+	}()
 
 	for !environments.AreReady() {
 		log.Info("Environments not set, retry in 3s...")
 		time.Sleep(3 * time.Second)
 	}
 
+	if len(checkpointed) > 0 {
+		resumed := messageHandler.ResumeChecks(checkpointed)
+		log.Infof("Resumed %d of the %d checks in progress before the restart", resumed, len(checkpointed))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	go consumer.Start(messageHandler.HandleMessage)
-	defer func() {
-		if err = consumer.Close(); err != nil {
-			log.WithError(err).Error("Error terminating consumer")
+
+	<-ctx.Done()
+	log.Info("Shutting down, waiting for the publish checks in progress")
+
+	// the publishes held back waiting for room in the scheduler are released, so that the consumer can stop
+	scheduler.StopScheduling()
+	if err = consumer.Close(); err != nil {
+		log.WithError(err).Error("Error terminating consumer")
+	}
+	if err = recorder.Close(); err != nil {
+		log.WithError(err).Error("Error closing the recording of consumed messages")
+	}
+
+	if interrupted := scheduler.Drain(context.Background()); interrupted > 0 {
+		log.Warnf("Interrupted %d publish checks which could not finish before the drain timeout", interrupted)
+	}
+
+	// the notifications checks read the feeds until they are over
+	stopWatching()
+	<-watchDone
+
+	// the manual check requests in progress are over, and no more can be made
+	if err = server.Shutdown(context.Background()); err != nil {
+		log.WithError(err).Error("Error shutting down the HTTP server")
+	}
+
+	// no check is left to send metrics, the destinations are flushed once the last ones are sent
+	close(metricSink)
+	<-aggregatorDone
+	if webhookDestination != nil {
+		if err = webhookDestination.Close(); err != nil {
+			log.WithError(err).Error("Error closing publish failure webhooks")
 		}
-	}()
+	}
+}
+
+// e2eTestUUIDs returns the UUIDs of the content published by the end-to-end tests of the capabilities.
+func e2eTestUUIDs(appConfig *config.AppConfig) []string {
+	var uuids []string
+	for _, c := range appConfig.Capabilities {
+		for _, id := range c.TestIDs {
+			if !sliceContains(uuids, id) {
+				uuids = append(uuids, id)
+			}
+		}
+	}
+	return uuids
+}
 
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
-	<-ch
+// newSplunkDestination returns the destination sending metrics to Splunk, either straight
+// to the HTTP Event Collector or through the logs, as key=value lines or JSON events.
+func newSplunkDestination(cfg config.SplunkConfig, log *logger.UPPLogger) metrics.Destination {
+	if cfg.HEC.URL != "" {
+		return metrics.NewSplunkHECSender(cfg.HEC, log)
+	}
+	if cfg.Format == splunkFormatJSON {
+		return metrics.NewJSONSplunkFeeder()
+	}
+	return metrics.NewSplunkFeeder(cfg.LogPrefix)
 }
 
+// startHTTPServer serves the API and the admin endpoints until the returned server is shut down.
 func startHTTPServer(
 	appConfig *config.AppConfig,
 	environments *envs.Environments,
 	subscribedFeeds map[string][]feeds.Feed,
 	metricContainer *metrics.History,
+	publishEvents *checks.PublishEvents,
+	inFlight *checks.InFlightChecks,
+	messageHandler MessageHandler,
+	prometheusDestination *metrics.PrometheusDestination,
+	sla *metrics.SLA,
+	slos *metrics.SLOs,
+	readAPIProtection *httpcaller.HostProtection,
 	consumer *kafka.Consumer,
 	log *logger.UPPLogger,
-) {
+) *http.Server {
 	router := mux.NewRouter()
 
-	hc := newHealthcheck(appConfig, metricContainer, environments, subscribedFeeds, consumer, log)
+	hc := newHealthcheck(appConfig, metricContainer, slos, readAPIProtection, environments, subscribedFeeds, consumer, log)
 	router.HandleFunc("/__health", hc.checkHealth())
 	router.HandleFunc(status.GTGPath, status.NewGoodToGoHandler(hc.GTG))
 
 	router.HandleFunc("/__history", loadHistory(metricContainer))
+	router.HandleFunc("/__publishes/{tid}", loadPublishEvent(publishEvents))
+	router.HandleFunc("/__checks", triggerChecks(messageHandler)).Methods(http.MethodPost)
+	router.HandleFunc("/__inflight", listInFlightChecks(inFlight)).Methods(http.MethodGet)
+	router.HandleFunc("/__inflight", cancelInFlightChecksForUUID(inFlight)).Methods(http.MethodDelete)
+	router.HandleFunc("/__inflight/{id}", cancelInFlightCheck(inFlight)).Methods(http.MethodDelete)
+	router.HandleFunc("/__sla", loadSLA(sla)).Methods(http.MethodGet)
+
+	router.Handle("/metrics", prometheusDestination.Handler())
 
 	router.HandleFunc(status.PingPath, status.PingHandler)
 	router.HandleFunc(status.PingPathDW, status.PingHandler)
@@ -187,17 +405,36 @@ func startHTTPServer(
 	router.HandleFunc(status.BuildInfoPath, status.BuildInfoHandler)
 	router.HandleFunc(status.BuildInfoPathDW, status.BuildInfoHandler)
 
-	http.Handle("/", router)
-	err := http.ListenAndServe(":8080", nil) //nolint:gosec
+	server := &http.Server{Addr: ":8080", Handler: router} //nolint:gosec
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Panicf("Couldn't set up HTTP listener: %+v\n", err)
+		}
+	}()
+	return server
+}
+
+// newMetricContainer returns the publish history, persisted to disk when a history file is configured.
+// Monitoring carries on with an in-memory history if the file cannot be used.
+func newMetricContainer(cfg config.HistoryConfig, log *logger.UPPLogger) *metrics.History {
+	if cfg.FilePath == "" {
+		return metrics.NewHistory(make([]metrics.PublishMetric, 0))
+	}
+
+	store, err := metrics.NewFileHistoryStore(cfg.FilePath)
 	if err != nil {
-		log.Panicf("Couldn't set up HTTP listener: %+v\n", err)
+		log.WithError(err).Error("Cannot open publish history store, history will not be persisted")
+		return metrics.NewHistory(make([]metrics.PublishMetric, 0))
 	}
-}
 
-func loadHistory(metricContainer *metrics.History) func(w http.ResponseWriter, r *http.Request) {
-	return func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, metricContainer.String())
+	history, err := metrics.NewPersistentHistory(store, cfg.MaxEntries, time.Duration(cfg.MaxAgeHours)*time.Hour, log)
+	if err != nil {
+		_ = store.Close()
+		log.WithError(err).Error("Cannot load publish history, history will not be persisted")
+		return metrics.NewHistory(make([]metrics.PublishMetric, 0))
 	}
+
+	return history
 }
 
 func sliceContains(s []string, e string) bool {
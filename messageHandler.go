@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -14,30 +16,65 @@ import (
 	"github.com/Financial-Times/publish-availability-monitor/envs"
 	"github.com/Financial-Times/publish-availability-monitor/feeds"
 	"github.com/Financial-Times/publish-availability-monitor/httpcaller"
-	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const systemIDKey = "Origin-System-Id"
 
+var (
+	// errInvalidMessage is returned for the messages whose content or publish date cannot be read.
+	errInvalidMessage = errors.New("invalid message")
+	// errIgnorableMessage is returned for the messages of synthetic or carousel publishes, which are not checked.
+	errIgnorableMessage = errors.New("ignorable message")
+	// errPublishNotChecked is returned, along with why, for the publishes the main pre-checks skip,
+	// such as invalid content or content published too long ago.
+	errPublishNotChecked = errors.New("publish not checked")
+	// errShuttingDown is returned for the publishes checked once the monitor started shutting down.
+	errShuttingDown = errors.New("the monitor is shutting down")
+)
+
+var tracer = otel.Tracer("github.com/Financial-Times/publish-availability-monitor")
+
 type MessageHandler interface {
 	HandleMessage(msg kafka.FTMessage)
+	CheckMessage(ctx context.Context, msg kafka.FTMessage, filter checks.CheckFilter) (int, error)
+	ResumeChecks(states []checks.CheckState) int
+}
+
+// MessageHandlerDeps are what the message handler schedules and runs the checks of the publishes with.
+type MessageHandlerDeps struct {
+	checks.SchedulerDeps
+	HTTPCaller httpcaller.Caller // calls the read APIs
+	Recorder   *messageRecorder  // records the consumed messages, nil if they are not recorded
 }
 
 func NewKafkaMessageHandler(
 	appConfig *config.AppConfig,
 	environments *envs.Environments,
 	subscribedFeeds map[string][]feeds.Feed,
-	metricSink chan metrics.PublishMetric,
-	metricContainer *metrics.History,
+	deps MessageHandlerDeps,
 	e2eTestUUIDs []string,
 	log *logger.UPPLogger,
 ) MessageHandler {
+	return newKafkaMessageHandler(appConfig, environments, subscribedFeeds, deps, e2eTestUUIDs, log)
+}
+
+func newKafkaMessageHandler(
+	appConfig *config.AppConfig,
+	environments *envs.Environments,
+	subscribedFeeds map[string][]feeds.Feed,
+	deps MessageHandlerDeps,
+	e2eTestUUIDs []string,
+	log *logger.UPPLogger,
+) *kafkaMessageHandler {
 	return &kafkaMessageHandler{
 		appConfig:       appConfig,
 		environments:    environments,
 		subscribedFeeds: subscribedFeeds,
-		metricSink:      metricSink,
-		metricContainer: metricContainer,
+		deps:            deps,
 		e2eTestUUIDs:    e2eTestUUIDs,
 		log:             log,
 	}
@@ -47,63 +84,153 @@ type kafkaMessageHandler struct {
 	appConfig       *config.AppConfig
 	environments    *envs.Environments
 	subscribedFeeds map[string][]feeds.Feed
-	metricSink      chan metrics.PublishMetric
-	metricContainer *metrics.History
+	deps            MessageHandlerDeps
 	e2eTestUUIDs    []string
 	log             *logger.UPPLogger
 }
 
 func (h *kafkaMessageHandler) HandleMessage(msg kafka.FTMessage) {
+	// the pre-checks log why they skip a publish
+	_, err := h.handleMessage(msg)
+	if err != nil && !errors.Is(err, errIgnorableMessage) && !errors.Is(err, errPublishNotChecked) {
+		h.log.WithTransactionID(msg.Headers["X-Request-Id"]).WithError(err).Warn("Cannot check publish")
+	}
+}
+
+// handleMessage records msg and schedules the checks of the publish it is about, unless it is ignorable.
+// It returns how many checks were scheduled.
+func (h *kafkaMessageHandler) handleMessage(msg kafka.FTMessage) (int, error) {
 	tid := msg.Headers["X-Request-Id"]
 	log := h.log.WithTransactionID(tid)
 
+	// every message starts a new trace, the checks of the publish are part of it
+	ctx, span := tracer.Start(context.Background(), "HandleMessage",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("transaction_id", tid),
+			attribute.String("messaging.source", msg.Topic),
+			attribute.String("origin_system_id", msg.Headers[systemIDKey]),
+			attribute.String("content_type", msg.Headers["Content-Type"]),
+		),
+	)
+	defer span.End()
+
 	log.Info("Received message")
 
+	if err := h.deps.Recorder.record(msg, time.Now()); err != nil {
+		log.WithError(err).Warn("Cannot record message")
+	}
+
 	if h.isIgnorableMessage(msg) {
 		log.Info("Message is ignorable. Skipping...")
-		return
+		span.SetAttributes(attribute.Bool("ignored", true))
+		return 0, errIgnorableMessage
 	}
 
+	return h.scheduleChecks(ctx, msg, checks.CheckFilter{})
+}
+
+// CheckMessage schedules the checks of the publish msg is about as if it was consumed,
+// restricted to the checks filter allows. Unlike HandleMessage, it does not skip synthetic or carousel publishes.
+// It returns how many checks were scheduled.
+func (h *kafkaMessageHandler) CheckMessage(ctx context.Context, msg kafka.FTMessage, filter checks.CheckFilter) (int, error) {
+	tid := msg.Headers["X-Request-Id"]
+
+	ctx, span := tracer.Start(ctx, "CheckMessage",
+		trace.WithAttributes(
+			attribute.String("transaction_id", tid),
+			attribute.String("origin_system_id", msg.Headers[systemIDKey]),
+			attribute.String("content_type", msg.Headers["Content-Type"]),
+		),
+	)
+	defer span.End()
+
+	h.log.WithTransactionID(tid).Info("Received manual check request")
+	if h.deps.Scheduler.Stopping() {
+		return 0, errShuttingDown
+	}
+	return h.scheduleChecks(ctx, msg, filter)
+}
+
+// scheduleChecks runs the main pre-checks on the content of msg and schedules the checks they allow.
+func (h *kafkaMessageHandler) scheduleChecks(ctx context.Context, msg kafka.FTMessage, filter checks.CheckFilter) (int, error) {
+	tid := msg.Headers["X-Request-Id"]
+	span := trace.SpanFromContext(ctx)
+
 	publishedContent, err := h.unmarshalContent(msg)
 	if err != nil {
-		h.log.WithError(err).Warn("Cannot unmarshal message")
-		return
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "cannot unmarshal message")
+		return 0, fmt.Errorf("%w: %v", errInvalidMessage, err)
 	}
+	span.SetAttributes(attribute.String("uuid", publishedContent.GetUUID()))
 
 	publishDateString := msg.Headers["Message-Timestamp"]
 	publishDate, err := time.Parse(checks.DateLayout, publishDateString)
 	if err != nil {
-		h.log.WithError(err).Errorf("Cannot parse publish date [%v]",
-			publishDateString)
-		return
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "cannot parse publish date")
+		return 0, fmt.Errorf("%w: cannot parse publish date [%v]: %v", errInvalidMessage, publishDateString, err)
 	}
 
 	var paramsToSchedule []*checks.SchedulerParam
 
 	for _, preCheck := range checks.MainPreChecks() {
-		ok, scheduleParam := preCheck(
+		scheduleParam, err := preCheck(
+			ctx,
 			publishedContent,
 			tid,
 			publishDate,
 			h.appConfig,
-			h.metricContainer,
 			h.environments,
 			h.log,
 		)
-		if ok {
-			paramsToSchedule = append(paramsToSchedule, scheduleParam)
-		} else {
+		if err != nil {
 			// if a main check is not ok, additional checks make no sense
-			return
+			span.SetAttributes(attribute.Bool("ignored", true))
+			return 0, fmt.Errorf("%w: %w", errPublishNotChecked, err)
 		}
+		scheduleParam.Restrict(filter)
+		paramsToSchedule = append(paramsToSchedule, scheduleParam)
+	}
+
+	endpointSpecificChecks := h.endpointSpecificChecks()
+
+	scheduled := 0
+	for _, scheduleParam := range paramsToSchedule {
+		scheduled += checks.ScheduleChecks(
+			ctx,
+			scheduleParam,
+			endpointSpecificChecks,
+			h.appConfig,
+			h.deps.SchedulerDeps,
+			h.e2eTestUUIDs,
+			h.log,
+		)
 	}
+	return scheduled, nil
+}
+
+// ResumeChecks schedules again the checks checkpointed before a restart, and returns how many were resumed.
+func (h *kafkaMessageHandler) ResumeChecks(states []checks.CheckState) int {
+	return checks.ResumeChecks(
+		context.Background(),
+		states,
+		h.endpointSpecificChecks(),
+		h.appConfig,
+		h.environments,
+		h.deps.SchedulerDeps,
+		h.log,
+	)
+}
 
-	hC := httpcaller.NewCaller(10)
+func (h *kafkaMessageHandler) endpointSpecificChecks() map[string]checks.EndpointSpecificCheck {
+	hC := h.deps.HTTPCaller
 
 	ml := strings.Split(h.appConfig.NotificationsPushPublicationMonitorList, ",")
 
 	// key is the endpoint alias from the config
-	endpointSpecificChecks := map[string]checks.EndpointSpecificCheck{
+	return map[string]checks.EndpointSpecificCheck{
 		"content":                  checks.NewContentCheck(hC),
 		"content-neo4j":            checks.NewContentNeo4jCheck(hC),
 		"content-collection-neo4j": checks.NewContentNeo4jCheck(hC),
@@ -150,17 +277,6 @@ func (h *kafkaMessageHandler) HandleMessage(msg kafka.FTMessage) {
 			"page-notifications-push",
 		),
 	}
-
-	for _, scheduleParam := range paramsToSchedule {
-		checks.ScheduleChecks(
-			scheduleParam,
-			endpointSpecificChecks,
-			h.appConfig,
-			h.metricSink,
-			h.e2eTestUUIDs,
-			h.log,
-		)
-	}
 }
 
 func (h *kafkaMessageHandler) isIgnorableMessage(msg kafka.FTMessage) bool {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -268,12 +269,24 @@ func TestHandleMessage_ProducesMetrics(t *testing.T) {
 			metricsCh := make(chan metrics.PublishMetric)
 			metricsHistory := metrics.NewHistory(make([]metrics.PublishMetric, 0))
 
+			scheduler := checks.NewCheckScheduler(config.SchedulerConfig{}, log)
+			go scheduler.Run()
+			defer scheduler.Stop()
+
 			mh := NewKafkaMessageHandler(
 				test.AppConfig,
 				testEnvs,
 				subscribedFeeds,
-				metricsCh,
-				metricsHistory,
+				MessageHandlerDeps{
+					SchedulerDeps: checks.SchedulerDeps{
+						MetricSink:    metricsCh,
+						History:       metricsHistory,
+						PublishEvents: checks.NewPublishEvents(10),
+						InFlight:      checks.NewInFlightChecks(),
+						Scheduler:     scheduler,
+					},
+					HTTPCaller: httpcaller.NewCaller(10),
+				},
 				test.E2ETestUUIDs,
 				log,
 			)
@@ -343,7 +356,7 @@ type mockHTTPCaller struct {
 	notifications []string
 }
 
-func (mht *mockHTTPCaller) DoCall(config httpcaller.Config) (*http.Response, error) {
+func (mht *mockHTTPCaller) DoCall(_ context.Context, config httpcaller.Config) (*http.Response, error) {
 	stream := &mockPushNotificationsStream{mht.notifications, 0}
 	return &http.Response{
 		StatusCode: 200,
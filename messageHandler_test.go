@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"testing"
 
 	"github.com/Financial-Times/go-logger/v2"
@@ -64,7 +65,7 @@ func TestTestIsIgnorableMessage_SyntheticE2ETest(t *testing.T) {
 	e2eTestUUIDs := []string{"e4d2885f-1140-400b-9407-921e1c7378cd"}
 	log := logger.NewUPPLogger("publish-availability-monitor", "INFO")
 
-	mh := NewKafkaMessageHandler(nil, nil, nil, nil, nil, e2eTestUUIDs, log)
+	mh := NewKafkaMessageHandler(nil, nil, nil, MessageHandlerDeps{}, e2eTestUUIDs, log)
 	kmh := mh.(*kafkaMessageHandler)
 
 	kafkaMessage := kafka.FTMessage{
@@ -107,7 +108,7 @@ func TestUnmarshalContent_ValidVideoMessage(t *testing.T) {
 			t.Errorf("Expected success, but error occurred [%v]", err)
 			return
 		}
-		valRes := resultContent.Validate("", "", "", "", log)
+		valRes := resultContent.Validate(context.Background(), "", "", "", "", log)
 		assert.False(t, valRes.IsMarkedDeleted, "Expected published content.")
 	}
 }
@@ -121,7 +122,7 @@ func TestUnmarshalContent_ValidDeletedVideoMessage(t *testing.T) {
 	}
 	log := logger.NewUPPLogger("test", "PANIC")
 
-	valRes := resultContent.Validate("", "", "", "", log)
+	valRes := resultContent.Validate(context.Background(), "", "", "", "", log)
 	assert.True(t, valRes.IsMarkedDeleted, "Expected deleted content.")
 }
 
@@ -134,7 +135,7 @@ func TestUnmarshalContent_InvalidVideoMessage(t *testing.T) {
 	}
 	log := logger.NewUPPLogger("test", "PANIC")
 
-	valRes := resultContent.Validate("", "", "", "", log)
+	valRes := resultContent.Validate(context.Background(), "", "", "", "", log)
 	assert.False(t, valRes.IsValid, "Expected invalid content.")
 }
 
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/kafka-client-go/v4"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+)
+
+const (
+	defaultRecorderMaxFileSizeMB  = 64
+	defaultRecorderMaxFileMinutes = 60
+	defaultRecorderMaxSizeMB      = 1024
+
+	recordingFilePrefix = "messages-"
+	recordingFileSuffix = ".jsonl"
+	// sorts the files of the archive in the order they were opened
+	recordingFileTimeLayout = "20060102T150405.000000000Z"
+
+	redactedHeaderValue = "REDACTED"
+)
+
+// alwaysRedactedHeaders carry credentials, their values are never recorded.
+var alwaysRedactedHeaders = []string{"Authorization", "Cookie", "X-Api-Key"}
+
+// messageRecorder writes the consumed messages to a local archive of JSONL files, one recordedMessage per line,
+// which can be replayed. The current file is rotated once too big or too old,
+// and the oldest files are deleted once the archive is too big or they are too old.
+// A nil *messageRecorder records nothing.
+type messageRecorder struct {
+	mu           sync.Mutex
+	dir          string
+	maxFileSize  int64
+	maxFileAge   time.Duration
+	maxSize      int64
+	maxAge       time.Duration
+	redacted     map[string]bool // lower case header names
+	file         *os.File
+	fileSize     int64
+	fileOpenedAt time.Time
+	now          func() time.Time
+}
+
+// newMessageRecorder returns the recorder configured by cfg, or nil if the messages are not recorded.
+func newMessageRecorder(cfg config.RecorderConfig) (*messageRecorder, error) {
+	if cfg.Dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create recording directory: %w", err)
+	}
+
+	r := &messageRecorder{
+		dir:         cfg.Dir,
+		maxFileSize: int64(cfg.MaxFileSizeMB) << 20,
+		maxFileAge:  time.Duration(cfg.MaxFileMinutes) * time.Minute,
+		maxSize:     int64(cfg.MaxSizeMB) << 20,
+		maxAge:      time.Duration(cfg.MaxAgeHours) * time.Hour,
+		redacted:    make(map[string]bool),
+		now:         time.Now,
+	}
+	if r.maxFileSize <= 0 {
+		r.maxFileSize = defaultRecorderMaxFileSizeMB << 20
+	}
+	if r.maxFileAge <= 0 {
+		r.maxFileAge = defaultRecorderMaxFileMinutes * time.Minute
+	}
+	if r.maxSize <= 0 {
+		r.maxSize = defaultRecorderMaxSizeMB << 20
+	}
+	for _, h := range alwaysRedactedHeaders {
+		r.redacted[strings.ToLower(h)] = true
+	}
+	for _, h := range cfg.RedactHeaders {
+		r.redacted[strings.ToLower(h)] = true
+	}
+
+	// the archive is otherwise only pruned once a message is recorded
+	if err := r.prune(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// record appends msg, received at receivedAt, to the current file of the archive.
+func (r *messageRecorder) record(msg kafka.FTMessage, receivedAt time.Time) error {
+	if r == nil {
+		return nil
+	}
+
+	headers := make(map[string]string, len(msg.Headers))
+	for k, v := range msg.Headers {
+		if r.redacted[strings.ToLower(k)] {
+			v = redactedHeaderValue
+		}
+		headers[k] = v
+	}
+
+	// the client does not tell the partition and offset of the messages
+	line, err := json.Marshal(recordedMessage{
+		Headers:    headers,
+		Body:       msg.Body,
+		Topic:      msg.Topic,
+		ReceivedAt: receivedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot encode message: %w", err)
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rotationDue(int64(len(line))) {
+		if err = r.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := r.file.Write(line)
+	r.fileSize += int64(n)
+	if err != nil {
+		return fmt.Errorf("cannot record message: %w", err)
+	}
+	return nil
+}
+
+// rotationDue tells whether the current file is missing, too old, or too big to take size more bytes.
+// A file always takes a first line, however big. Callers must hold the lock.
+func (r *messageRecorder) rotationDue(size int64) bool {
+	if r.file == nil || r.now().Sub(r.fileOpenedAt) >= r.maxFileAge {
+		return true
+	}
+	return r.fileSize > 0 && r.fileSize+size > r.maxFileSize
+}
+
+// rotate closes the current file, opens a new one and deletes the files the archive cannot keep.
+// Callers must hold the lock.
+func (r *messageRecorder) rotate() error {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			return fmt.Errorf("cannot close recording file: %w", err)
+		}
+		r.file = nil
+	}
+
+	openedAt := r.now()
+	path := filepath.Join(r.dir, recordingFilePrefix+openedAt.UTC().Format(recordingFileTimeLayout)+recordingFileSuffix)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot open recording file [%s]: %w", path, err)
+	}
+	r.file = f
+	r.fileSize = 0
+	r.fileOpenedAt = openedAt
+
+	return r.prune()
+}
+
+// prune deletes the oldest files of the archive, but the current one if any, while they are too old or the archive too big.
+// Callers must hold the lock.
+func (r *messageRecorder) prune() error {
+	paths, err := filepath.Glob(filepath.Join(r.dir, recordingFilePrefix+"*"+recordingFileSuffix))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	type recording struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var recordings []recording
+	var size int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		recordings = append(recordings, recording{path: path, size: info.Size(), modTime: info.ModTime()})
+		size += info.Size()
+	}
+
+	current := ""
+	if r.file != nil {
+		current = r.file.Name()
+	}
+	for _, rec := range recordings {
+		tooOld := r.maxAge > 0 && r.now().Sub(rec.modTime) > r.maxAge
+		if rec.path == current || !tooOld && size <= r.maxSize {
+			continue
+		}
+		if err = os.Remove(rec.path); err != nil {
+			return fmt.Errorf("cannot delete recording file [%s]: %w", rec.path, err)
+		}
+		size -= rec.size
+	}
+	return nil
+}
+
+// Close prunes the archive and closes its current file.
+func (r *messageRecorder) Close() error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.prune()
+	if r.file == nil {
+		return err
+	}
+	err = errors.Join(err, r.file.Close())
+	r.file = nil
+	return err
+}
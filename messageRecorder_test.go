@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/kafka-client-go/v4"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordings(t *testing.T, dir string) []string {
+	paths, err := filepath.Glob(filepath.Join(dir, recordingFilePrefix+"*"+recordingFileSuffix))
+	require.NoError(t, err)
+	return paths
+}
+
+func TestMessageRecorderDisabled(t *testing.T) {
+	r, err := newMessageRecorder(config.RecorderConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	assert.NoError(t, r.record(kafka.FTMessage{}, time.Now()))
+	assert.NoError(t, r.Close())
+}
+
+func TestMessageRecorderRecordsReplayableMessages(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "recordings")
+	r, err := newMessageRecorder(config.RecorderConfig{Dir: dir, RedactHeaders: []string{"X-Origin-Token"}})
+	require.NoError(t, err)
+
+	receivedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg := kafka.FTMessage{
+		Headers: map[string]string{
+			"X-Request-Id":   "tid_1",
+			"authorization":  "Basic c2VjcmV0",
+			"X-Origin-Token": "secret",
+		},
+		Body:  `{"uuid": "077f5ac2-0491-420e-a5d0-982e0f86204b"}`,
+		Topic: "PreNativeCmsPublicationEvents",
+	}
+	require.NoError(t, r.record(msg, receivedAt))
+	require.NoError(t, r.Close())
+	assert.Equal(t, "Basic c2VjcmV0", msg.Headers["authorization"], "the consumed message should not change")
+
+	paths := recordings(t, dir)
+	require.Len(t, paths, 1)
+	f, err := os.Open(paths[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	messages, err := readRecordedMessages(f)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, map[string]string{
+		"X-Request-Id":   "tid_1",
+		"authorization":  redactedHeaderValue,
+		"X-Origin-Token": redactedHeaderValue,
+	}, messages[0].Headers)
+	assert.Equal(t, msg.Body, messages[0].Body)
+	assert.Equal(t, msg.Topic, messages[0].Topic)
+	assert.True(t, receivedAt.Equal(messages[0].ReceivedAt))
+}
+
+func TestMessageRecorderRotatesFiles(t *testing.T) {
+	dir := t.TempDir()
+	r, err := newMessageRecorder(config.RecorderConfig{Dir: dir})
+	require.NoError(t, err)
+	defer r.Close()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	r.maxFileSize = 200
+	msg := kafka.FTMessage{Headers: map[string]string{"X-Request-Id": "tid_1"}, Body: strings.Repeat("a", 100)}
+
+	require.NoError(t, r.record(msg, now))
+	assert.Len(t, recordings(t, dir), 1)
+
+	now = now.Add(time.Millisecond)
+	require.NoError(t, r.record(msg, now))
+	assert.Len(t, recordings(t, dir), 2, "a file too big for the message should be rotated")
+
+	now = now.Add(defaultRecorderMaxFileMinutes * time.Minute)
+	require.NoError(t, r.record(kafka.FTMessage{}, now))
+	assert.Len(t, recordings(t, dir), 3, "a file too old should be rotated")
+}
+
+func TestMessageRecorderDeletesOldestFiles(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, recordingFilePrefix+"20240101T000000.000000000Z"+recordingFileSuffix)
+	older := filepath.Join(dir, recordingFilePrefix+"20231231T000000.000000000Z"+recordingFileSuffix)
+	recent := filepath.Join(dir, recordingFilePrefix+"20240102T000000.000000000Z"+recordingFileSuffix)
+	for _, path := range []string{older, old, recent} {
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("a", 100)+"\n"), 0o600))
+	}
+	require.NoError(t, os.Chtimes(older, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+
+	r, err := newMessageRecorder(config.RecorderConfig{Dir: dir, MaxAgeHours: 24})
+	require.NoError(t, err)
+	defer r.Close()
+	r.maxSize = 150
+
+	require.NoError(t, r.record(kafka.FTMessage{}, time.Now()))
+
+	paths := recordings(t, dir)
+	require.Len(t, paths, 2)
+	assert.Equal(t, recent, paths[0], "the files too old, then the oldest files while the archive is too big, should be deleted")
+}
+
+func TestMessageRecorderDeletesOldFilesWithoutRecording(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, recordingFilePrefix+"20240101T000000.000000000Z"+recordingFileSuffix)
+	recent := filepath.Join(dir, recordingFilePrefix+"20240102T000000.000000000Z"+recordingFileSuffix)
+	for _, path := range []string{old, recent} {
+		require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o600))
+	}
+	require.NoError(t, os.Chtimes(old, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+
+	r, err := newMessageRecorder(config.RecorderConfig{Dir: dir, MaxAgeHours: 24})
+	require.NoError(t, err)
+	assert.Equal(t, []string{recent}, recordings(t, dir), "the files too old should be deleted on startup")
+
+	require.NoError(t, os.Chtimes(recent, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+	require.NoError(t, r.Close())
+	assert.Empty(t, recordings(t, dir), "the files too old should be deleted on close")
+}
@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"sync"
+
 	"github.com/Financial-Times/go-logger/v2"
 )
 
@@ -16,6 +18,7 @@ type Aggregator struct {
 	publishMetricSource          chan PublishMetric
 	publishMetricDestinations    []Destination
 	capabilityMetricDestinations []Destination
+	sending                      sync.WaitGroup
 	log                          *logger.UPPLogger
 }
 
@@ -32,20 +35,30 @@ func NewAggregator(inputChannel chan PublishMetric, publishMetricDestinations, c
 
 // Run reads PublishMetrics from a channel and distributes them to a list of
 // Destinations.
-// Stops reading when the channel is closed.
+// Stops reading when the channel is closed, and returns once the metrics read are sent.
 func (a *Aggregator) Run() {
+	defer a.sending.Wait()
+
 	for publishMetric := range a.publishMetricSource {
 		if publishMetric.Capability != nil {
 			a.log.Infof("Got a E2E metric [%s] in aggregator", publishMetric.String())
 			for _, sender := range a.capabilityMetricDestinations {
-				go sender.Send(publishMetric)
+				a.send(sender, publishMetric)
 			}
 
 			continue
 		}
 
 		for _, sender := range a.publishMetricDestinations {
-			go sender.Send(publishMetric)
+			a.send(sender, publishMetric)
 		}
 	}
 }
+
+func (a *Aggregator) send(destination Destination, pm PublishMetric) {
+	a.sending.Add(1)
+	go func() {
+		defer a.sending.Done()
+		destination.Send(pm)
+	}()
+}
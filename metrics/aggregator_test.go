@@ -3,6 +3,7 @@ package metrics
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/Financial-Times/publish-availability-monitor/config"
@@ -62,6 +63,44 @@ func TestAggregatorRun(t *testing.T) {
 	}
 }
 
+func TestAggregatorRunWaitsForSends(t *testing.T) {
+	release := make(chan struct{})
+	metricsCh := make(chan PublishMetric)
+	aggregator := NewAggregator(metricsCh,
+		[]Destination{blockingDestination{release: release}},
+		nil,
+		logger.NewUPPLogger("test", "PANIC"))
+
+	done := make(chan struct{})
+	go func() {
+		aggregator.Run()
+		close(done)
+	}()
+	metricsCh <- PublishMetric{}
+	close(metricsCh)
+
+	select {
+	case <-done:
+		t.Fatal("expected Run to wait for the metric to be sent")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return once the metric was sent")
+	}
+}
+
+type blockingDestination struct {
+	release chan struct{}
+}
+
+func (bd blockingDestination) Send(_ PublishMetric) {
+	<-bd.release
+}
+
 type mockDestination struct {
 	metrics   []PublishMetric
 	waitGroup *sync.WaitGroup
@@ -1,60 +1,270 @@
 package metrics
 
 import (
+	"errors"
 	"fmt"
-	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/Financial-Times/publish-availability-monitor/config"
 )
 
+const (
+	defaultGraphiteConnections     = 1
+	defaultGraphiteBufferSize      = 10000
+	defaultGraphiteSpoolMaxMetrics = 100000
+	defaultGraphiteBatchSize       = 500
+	defaultGraphiteFlushInterval   = time.Second
+	graphiteCloseTimeout           = 5 * time.Second
+)
+
 // GraphiteSender implements Destination interface to send PublishMetrics for capability E2E tests to Graphite.
+// Metrics are buffered by Send and written in batches by Run over long-lived connections.
+// While Graphite is unreachable the metrics are kept in a bounded buffer, overflowing to the optional
+// spool file, and dropped once both are full.
 type GraphiteSender struct {
 	graphiteAddress string
 	graphiteUUID    string
 	environment     string
-	log             *logger.UPPLogger
+	batchSize       int
+	bufferSize      int
+	flushInterval   time.Duration
+
+	mu      sync.Mutex
+	buffer  []graphiteMetric
+	spool   *graphiteSpool // nil if metrics overflowing the buffer are dropped
+	conns   []*graphiteConn
+	next    int // connection used by the next flush
+	dropped atomic.Uint64
+
+	running   atomic.Bool
+	flushCh   chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	log       *logger.UPPLogger
 }
 
 // NewGraphiteSender returns a GraphiteSender.
 func NewGraphiteSender(cfg *config.AppConfig, log *logger.UPPLogger) *GraphiteSender {
-	return &GraphiteSender{
+	gc := cfg.GraphiteConf
+	gs := &GraphiteSender{
 		graphiteAddress: cfg.GraphiteAddress,
 		graphiteUUID:    cfg.GraphiteUUID,
 		environment:     cfg.Environment,
+		batchSize:       orDefault(gc.BatchSize, defaultGraphiteBatchSize),
+		bufferSize:      orDefault(gc.BufferSize, defaultGraphiteBufferSize),
+		flushInterval:   time.Duration(gc.FlushIntervalSeconds) * time.Second,
+		flushCh:         make(chan struct{}, 1),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
 		log:             log,
 	}
+	if gs.flushInterval <= 0 {
+		gs.flushInterval = defaultGraphiteFlushInterval
+	}
+
+	for i := 0; i < orDefault(gc.Connections, defaultGraphiteConnections); i++ {
+		gs.conns = append(gs.conns, newGraphiteConn(cfg.GraphiteAddress, gc.Protocol))
+	}
+
+	if gc.SpoolFilePath != "" {
+		spool, err := openGraphiteSpool(gc.SpoolFilePath, orDefault(gc.SpoolMaxMetrics, defaultGraphiteSpoolMaxMetrics))
+		if err != nil {
+			log.WithError(err).Error("Cannot open Graphite spool file, metrics overflowing the buffer will be dropped")
+		} else {
+			gs.spool = spool
+		}
+	}
+	return gs
+}
+
+func orDefault(value, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
 }
 
-// Send transforms a Publish metric to Graphite one and sends it to Graphite endpoint.
+// Send transforms a Publish metric to Graphite ones and buffers them to be sent by Run.
 func (gs *GraphiteSender) Send(pm PublishMetric) {
 	if pm.Capability == nil {
 		gs.log.Errorf("Cannot send non-capability metric %s to Graphite", pm.Config.Alias)
 		return
 	}
+	if !pm.GetOutcome().IsSLAOutcome() {
+		return
+	}
 
 	metricPrefix := fmt.Sprintf("%s.%s.%s", gs.graphiteUUID, pm.Capability.Name, gs.environment)
-	statusMetricName := fmt.Sprintf("%s.%s", metricPrefix, "status")
-	var statusMetricValue int
+	var statusMetricValue float64
 	if pm.PublishOK {
 		statusMetricValue = 1
 	}
-	statusMetric := fmt.Sprintf("%s %d %d\n", statusMetricName, statusMetricValue, time.Now().Unix())
+	now := time.Now().Unix()
 
-	timeMetricName := fmt.Sprintf("%s.%s", metricPrefix, "time")
-	timeMetricValue := pm.PublishInterval.UpperBound
-	timeMetric := fmt.Sprintf("%s %d %d\n", timeMetricName, timeMetricValue, time.Now().Unix())
+	metrics := []graphiteMetric{
+		{Path: metricPrefix + ".status", Value: statusMetricValue, Timestamp: now},
+		{Path: metricPrefix + ".time", Value: float64(pm.PublishInterval.UpperBound), Timestamp: now},
+	}
+	if latency, found := pm.Latency(); found {
+		metrics = append(metrics, graphiteMetric{Path: metricPrefix + ".latency", Value: latency.Seconds(), Timestamp: now})
+	}
+	gs.enqueue(metrics...)
+}
+
+// SendGauges buffers the gauges to be sent by Run, under the Graphite UUID.
+func (gs *GraphiteSender) SendGauges(gauges []Gauge, at time.Time) {
+	metrics := make([]graphiteMetric, 0, len(gauges))
+	for _, g := range gauges {
+		metrics = append(metrics, graphiteMetric{Path: gs.graphiteUUID + "." + g.Path, Value: g.Value, Timestamp: at.Unix()})
+	}
+	gs.enqueue(metrics...)
+}
+
+func (gs *GraphiteSender) enqueue(metrics ...graphiteMetric) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	for _, m := range metrics {
+		gs.bufferLocked(m)
+	}
+
+	if len(gs.buffer) >= gs.batchSize {
+		select {
+		case gs.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
 
-	conn, err := net.DialTimeout("tcp", gs.graphiteAddress, 10*time.Second)
-	if err != nil {
-		gs.log.WithError(err).Error("Cannot connect to Graphite")
+// bufferLocked keeps m in memory, or in the spool if the buffer is full, or drops it.
+func (gs *GraphiteSender) bufferLocked(m graphiteMetric) {
+	if len(gs.buffer) < gs.bufferSize {
+		gs.buffer = append(gs.buffer, m)
 		return
 	}
-	defer conn.Close()
+	if gs.spool != nil && gs.spool.write(m) {
+		return
+	}
+
+	gs.dropped.Add(1)
+	gs.log.Warnf("Graphite buffer is full, dropping metric %s", m.Path)
+}
+
+// Dropped returns how many metrics were dropped because Graphite could not keep up.
+func (gs *GraphiteSender) Dropped() uint64 {
+	return gs.dropped.Load()
+}
+
+// Run writes the buffered metrics to Graphite every flush interval, or as soon as
+// a batch is complete, until Close is called.
+func (gs *GraphiteSender) Run() {
+	gs.running.Store(true)
+	defer close(gs.done)
+
+	ticker := time.NewTicker(gs.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-gs.stop:
+			gs.flush()
+			return
+		case <-ticker.C:
+		case <-gs.flushCh:
+		}
+		gs.flush()
+	}
+}
+
+// flush writes batches to Graphite until the buffer and the spool are empty or a write fails.
+func (gs *GraphiteSender) flush() {
+	for {
+		batch := gs.nextBatch()
+		if len(batch) == 0 {
+			return
+		}
+
+		conn := gs.conns[gs.next]
+		gs.next = (gs.next + 1) % len(gs.conns)
+		if err := conn.write(batch); err != nil {
+			if !errors.Is(err, errGraphiteBackoff) {
+				gs.log.WithError(err).Warnf("Cannot write %d metrics to Graphite, they will be retried", len(batch))
+			}
+			gs.requeue(batch)
+			return
+		}
+	}
+}
+
+func (gs *GraphiteSender) nextBatch() []graphiteMetric {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if len(gs.buffer) == 0 && gs.spool != nil {
+		spooled, err := gs.spool.take(gs.batchSize)
+		if err != nil {
+			gs.log.WithError(err).Error("Cannot read spooled Graphite metrics")
+		}
+		gs.buffer = append(gs.buffer, spooled...)
+	}
+
+	n := gs.batchSize
+	if n > len(gs.buffer) {
+		n = len(gs.buffer)
+	}
+	batch := append([]graphiteMetric(nil), gs.buffer[:n]...)
+	gs.buffer = gs.buffer[n:]
+	return batch
+}
+
+// requeue puts back a batch which could not be written in front of the buffer.
+func (gs *GraphiteSender) requeue(batch []graphiteMetric) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	pending := gs.buffer
+	gs.buffer = make([]graphiteMetric, 0, len(batch)+len(pending))
+	for _, m := range append(batch, pending...) {
+		gs.bufferLocked(m)
+	}
+}
+
+// Close stops Run after a last flush, spools the metrics which could not be written and closes the connections.
+func (gs *GraphiteSender) Close() error {
+	gs.closeOnce.Do(func() {
+		close(gs.stop)
+	})
+
+	if gs.running.Load() {
+		select {
+		case <-gs.done:
+		case <-time.After(graphiteCloseTimeout):
+			return errors.New("timed out waiting for the last flush to Graphite")
+		}
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	for _, conn := range gs.conns {
+		conn.close()
+	}
+	if gs.spool == nil {
+		if len(gs.buffer) > 0 {
+			gs.log.Warnf("Dropping %d metrics which could not be written to Graphite", len(gs.buffer))
+		}
+		return nil
+	}
 
-	_, err = fmt.Fprint(conn, statusMetric, timeMetric)
-	if err != nil {
-		gs.log.WithError(err).Error("Cannot write Graphite metric")
+	for _, m := range gs.buffer {
+		if !gs.spool.write(m) {
+			gs.dropped.Add(1)
+		}
 	}
+	gs.buffer = nil
+	return gs.spool.close()
 }
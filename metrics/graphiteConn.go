@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	GraphiteProtocolTCP    = "tcp"
+	GraphiteProtocolUDP    = "udp"
+	GraphiteProtocolPickle = "pickle"
+
+	graphiteDialTimeout  = 10 * time.Second
+	graphiteWriteTimeout = 10 * time.Second
+	graphiteMinBackoff   = time.Second
+	graphiteMaxBackoff   = time.Minute
+	// keeps UDP datagrams below the usual MTU
+	graphiteMaxDatagramSize = 1400
+)
+
+var errGraphiteBackoff = errors.New("waiting before reconnecting to Graphite")
+
+// graphiteMetric is a single data point sent to Graphite.
+type graphiteMetric struct {
+	Path      string
+	Value     float64
+	Timestamp int64
+}
+
+// plaintext returns m as a line of the Graphite plaintext protocol.
+func (m graphiteMetric) plaintext() string {
+	return fmt.Sprintf("%s %s %d\n", m.Path, strconv.FormatFloat(m.Value, 'f', -1, 64), m.Timestamp)
+}
+
+func parseGraphitePlaintext(line string) (graphiteMetric, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return graphiteMetric{}, fmt.Errorf("invalid Graphite metric [%s]", line)
+	}
+
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return graphiteMetric{}, fmt.Errorf("invalid Graphite metric value [%s]: %w", line, err)
+	}
+	timestamp, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return graphiteMetric{}, fmt.Errorf("invalid Graphite metric timestamp [%s]: %w", line, err)
+	}
+	return graphiteMetric{Path: fields[0], Value: value, Timestamp: timestamp}, nil
+}
+
+// graphiteConn is a long-lived connection to Graphite which reconnects with an exponential backoff.
+// It is not safe for concurrent use.
+type graphiteConn struct {
+	address     string
+	protocol    string
+	conn        net.Conn
+	backoff     time.Duration
+	nextAttempt time.Time
+}
+
+func newGraphiteConn(address, protocol string) *graphiteConn {
+	return &graphiteConn{address: address, protocol: protocol}
+}
+
+// write sends batch to Graphite, connecting first if needed.
+// On error the connection is closed and reopened by a later write.
+func (c *graphiteConn) write(batch []graphiteMetric) error {
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	payloads, err := c.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(graphiteWriteTimeout))
+	for _, payload := range payloads {
+		if _, err = c.conn.Write(payload); err != nil {
+			c.close()
+			c.failed()
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *graphiteConn) connect() error {
+	if c.conn != nil {
+		return nil
+	}
+	if time.Now().Before(c.nextAttempt) {
+		return errGraphiteBackoff
+	}
+
+	network := "tcp"
+	if c.protocol == GraphiteProtocolUDP {
+		network = "udp"
+	}
+	conn, err := net.DialTimeout(network, c.address, graphiteDialTimeout)
+	if err != nil {
+		c.failed()
+		return err
+	}
+
+	c.conn = conn
+	c.backoff = 0
+	return nil
+}
+
+// failed doubles the time to wait before the next connection attempt.
+func (c *graphiteConn) failed() {
+	c.backoff *= 2
+	if c.backoff < graphiteMinBackoff {
+		c.backoff = graphiteMinBackoff
+	}
+	if c.backoff > graphiteMaxBackoff {
+		c.backoff = graphiteMaxBackoff
+	}
+	c.nextAttempt = time.Now().Add(c.backoff)
+}
+
+func (c *graphiteConn) close() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+}
+
+func (c *graphiteConn) encode(batch []graphiteMetric) ([][]byte, error) {
+	switch c.protocol {
+	case GraphiteProtocolPickle:
+		return [][]byte{encodeGraphitePickle(batch)}, nil
+	case GraphiteProtocolUDP:
+		return encodeGraphiteDatagrams(batch), nil
+	case GraphiteProtocolTCP, "":
+		var buf bytes.Buffer
+		for _, m := range batch {
+			buf.WriteString(m.plaintext())
+		}
+		return [][]byte{buf.Bytes()}, nil
+	default:
+		return nil, fmt.Errorf("unknown Graphite protocol [%s]", c.protocol)
+	}
+}
+
+// encodeGraphiteDatagrams splits the plaintext batch in datagrams, without splitting any line.
+func encodeGraphiteDatagrams(batch []graphiteMetric) [][]byte {
+	var datagrams [][]byte
+	var buf bytes.Buffer
+	for _, m := range batch {
+		line := m.plaintext()
+		if buf.Len() > 0 && buf.Len()+len(line) > graphiteMaxDatagramSize {
+			datagrams = append(datagrams, append([]byte(nil), buf.Bytes()...))
+			buf.Reset()
+		}
+		buf.WriteString(line)
+	}
+	if buf.Len() > 0 {
+		datagrams = append(datagrams, buf.Bytes())
+	}
+	return datagrams
+}
+
+// encodeGraphitePickle returns the batch in the Graphite pickle protocol: a 4 bytes big endian length
+// followed by a protocol 2 pickle of a list of (path, (timestamp, value)) tuples.
+func encodeGraphitePickle(batch []graphiteMetric) []byte {
+	var p bytes.Buffer
+	p.Write([]byte{0x80, 0x02}) // PROTO 2
+	p.WriteByte(']')            // EMPTY_LIST
+	p.WriteByte('(')            // MARK
+	for _, m := range batch {
+		p.WriteByte('X') // BINUNICODE
+		_ = binary.Write(&p, binary.LittleEndian, uint32(len(m.Path)))
+		p.WriteString(m.Path)
+
+		p.WriteByte('J') // BININT
+		_ = binary.Write(&p, binary.LittleEndian, int32(m.Timestamp))
+		p.WriteByte('G') // BINFLOAT
+		_ = binary.Write(&p, binary.BigEndian, math.Float64bits(m.Value))
+
+		p.WriteByte(0x86) // TUPLE2 (timestamp, value)
+		p.WriteByte(0x86) // TUPLE2 (path, (timestamp, value))
+	}
+	p.WriteByte('e') // APPENDS
+	p.WriteByte('.') // STOP
+
+	payload := make([]byte, 4, 4+p.Len())
+	binary.BigEndian.PutUint32(payload, uint32(p.Len()))
+	return append(payload, p.Bytes()...)
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphitePlaintext(t *testing.T) {
+	m := graphiteMetric{Path: "a.b.time", Value: 3, Timestamp: 1700000000}
+	assert.Equal(t, "a.b.time 3 1700000000\n", m.plaintext())
+
+	parsed, err := parseGraphitePlaintext(strings.TrimSpace(m.plaintext()))
+	require.NoError(t, err)
+	assert.Equal(t, m, parsed)
+
+	_, err = parseGraphitePlaintext("a.b.time 3")
+	assert.Error(t, err)
+}
+
+func TestEncodeGraphitePickle(t *testing.T) {
+	payload := encodeGraphitePickle([]graphiteMetric{
+		{Path: "a.b.status", Value: 1, Timestamp: 1700000000},
+		{Path: "a.b.time", Value: 3.5, Timestamp: 1700000001},
+	})
+
+	// pickle.loads(payload[4:]) == [('a.b.status', (1700000000, 1.0)), ('a.b.time', (1700000001, 3.5))]
+	expected := "00000042" + "80025d28" +
+		"580a000000612e622e737461747573" + "4a00f15365" + "473ff0000000000000" + "8686" +
+		"5808000000612e622e74696d65" + "4a01f15365" + "47400c000000000000" + "8686" +
+		"652e"
+	assert.Equal(t, expected, hex.EncodeToString(payload))
+}
+
+func TestEncodeGraphiteDatagramsDoesNotSplitLines(t *testing.T) {
+	var batch []graphiteMetric
+	for i := 0; i < 100; i++ {
+		batch = append(batch, graphiteMetric{Path: "e435d5ef-20da-4c61-928c-71bfbec9fa3e.test-capability.test.status", Value: 1, Timestamp: 1700000000})
+	}
+
+	datagrams := encodeGraphiteDatagrams(batch)
+
+	require.Greater(t, len(datagrams), 1)
+	lines := 0
+	for _, d := range datagrams {
+		assert.LessOrEqual(t, len(d), graphiteMaxDatagramSize)
+		assert.True(t, strings.HasSuffix(string(d), "\n"))
+		lines += strings.Count(string(d), "\n")
+	}
+	assert.Equal(t, 100, lines)
+}
+
+func TestGraphiteConnBackoff(t *testing.T) {
+	c := newGraphiteConn(unusedAddress(t), GraphiteProtocolTCP)
+
+	err := c.write([]graphiteMetric{{Path: "a", Value: 1, Timestamp: 1}})
+	require.Error(t, err)
+	assert.Equal(t, graphiteMinBackoff, c.backoff)
+
+	assert.ErrorIs(t, c.write([]graphiteMetric{{Path: "a", Value: 1, Timestamp: 1}}), errGraphiteBackoff)
+
+	c.nextAttempt = time.Time{}
+	require.Error(t, c.write([]graphiteMetric{{Path: "a", Value: 1, Timestamp: 1}}))
+	assert.Equal(t, 2*graphiteMinBackoff, c.backoff, "backoff should double after every failed attempt")
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// graphiteSpool keeps the metrics overflowing the in-memory buffer of a GraphiteSender
+// in a file, as plaintext protocol lines. It is not safe for concurrent use.
+type graphiteSpool struct {
+	path       string
+	maxMetrics int
+	count      int
+	file       *os.File
+}
+
+func openGraphiteSpool(path string, maxMetrics int) (*graphiteSpool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create Graphite spool directory: %w", err)
+	}
+
+	s := &graphiteSpool{path: path, maxMetrics: maxMetrics}
+	metrics, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	s.count = len(metrics)
+
+	if err = s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *graphiteSpool) open() error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot open Graphite spool file [%s]: %w", s.path, err)
+	}
+	s.file = f
+	return nil
+}
+
+// load reads the spooled metrics, skipping the lines which cannot be parsed.
+func (s *graphiteSpool) load() ([]graphiteMetric, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Graphite spool file [%s]: %w", s.path, err)
+	}
+	defer f.Close()
+
+	var metrics []graphiteMetric
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m, err := parseGraphitePlaintext(scanner.Text())
+		if err != nil {
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, scanner.Err()
+}
+
+// write spools m, returning false if the spool is full.
+func (s *graphiteSpool) write(m graphiteMetric) bool {
+	if s.count >= s.maxMetrics {
+		return false
+	}
+	if _, err := s.file.WriteString(m.plaintext()); err != nil {
+		return false
+	}
+	s.count++
+	return true
+}
+
+// take removes and returns up to n of the oldest spooled metrics.
+func (s *graphiteSpool) take(n int) ([]graphiteMetric, error) {
+	if s.count == 0 {
+		return nil, nil
+	}
+
+	metrics, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if n > len(metrics) {
+		n = len(metrics)
+	}
+	taken, rest := metrics[:n], metrics[n:]
+
+	var remaining strings.Builder
+	for _, m := range rest {
+		remaining.WriteString(m.plaintext())
+	}
+
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, []byte(remaining.String()), 0o600); err != nil {
+		return nil, fmt.Errorf("cannot rewrite Graphite spool file [%s]: %w", s.path, err)
+	}
+	_ = s.file.Close()
+	renameErr := os.Rename(tmp, s.path)
+	if err = s.open(); err != nil {
+		return nil, err
+	}
+	if renameErr != nil {
+		return nil, fmt.Errorf("cannot rewrite Graphite spool file [%s]: %w", s.path, renameErr)
+	}
+
+	s.count = len(rest)
+	return taken, nil
+}
+
+func (s *graphiteSpool) close() error {
+	return s.file.Close()
+}
@@ -1,8 +1,9 @@
 package metrics
 
 import (
-	"io"
+	"bufio"
 	"net"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -87,6 +88,9 @@ func TestGraphiteSend(t *testing.T) {
 			test.AppConfig.GraphiteAddress = srv.Addr().String()
 
 			sender := NewGraphiteSender(test.AppConfig, log)
+			sender.flushInterval = 10 * time.Millisecond
+			go sender.Run()
+			defer sender.Close()
 			metricConfig := test.AppConfig.MetricConf[0]
 			metric := PublishMetric{
 				PublishOK:       test.PublishOk,
@@ -107,10 +111,16 @@ func TestGraphiteSend(t *testing.T) {
 				}
 				defer conn.Close()
 
-				buf, err := io.ReadAll(conn)
-				if err != nil {
-					errCh <- err
-					return
+				// the connection is kept open, so only the two expected lines are read
+				reader := bufio.NewReader(conn)
+				var buf []byte
+				for i := 0; i < 2; i++ {
+					line, err := reader.ReadBytes('\n')
+					if err != nil {
+						errCh <- err
+						return
+					}
+					buf = append(buf, line...)
 				}
 				resultCh <- buf
 			}()
@@ -135,3 +145,152 @@ func TestGraphiteSend(t *testing.T) {
 		})
 	}
 }
+
+func newTestGraphiteSender(t *testing.T, address string, gc config.GraphiteConfig) *GraphiteSender {
+	return NewGraphiteSender(&config.AppConfig{
+		GraphiteAddress: address,
+		GraphiteUUID:    "e435d5ef-20da-4c61-928c-71bfbec9fa3e",
+		Environment:     "test",
+		GraphiteConf:    gc,
+	}, logger.NewUPPLogger("test", "PANIC"))
+}
+
+func capabilityMetric(publishOK bool) PublishMetric {
+	return PublishMetric{
+		PublishOK:       publishOK,
+		Capability:      &config.Capability{Name: "test-capability"},
+		PublishInterval: Interval{UpperBound: 3},
+	}
+}
+
+// unusedAddress returns a local address nothing listens on.
+func unusedAddress(t *testing.T) string {
+	srv, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := srv.Addr().String()
+	require.NoError(t, srv.Close())
+	return address
+}
+
+func readGraphiteLines(t *testing.T, srv net.Listener, n int) []string {
+	conn, err := srv.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	reader := bufio.NewReader(conn)
+	lines := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestGraphiteSenderBuffersUntilGraphiteIsReachable(t *testing.T) {
+	address := unusedAddress(t)
+	sender := newTestGraphiteSender(t, address, config.GraphiteConfig{})
+
+	sender.Send(capabilityMetric(true))
+	sender.flush()
+	assert.Len(t, sender.buffer, 2, "metrics should be kept while Graphite is unreachable")
+	assert.True(t, sender.conns[0].nextAttempt.After(time.Now()), "reconnection should be delayed")
+
+	srv, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	sender.conns[0].nextAttempt = time.Time{}
+	sender.flush()
+	defer sender.Close()
+
+	lines := readGraphiteLines(t, srv, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "e435d5ef-20da-4c61-928c-71bfbec9fa3e.test-capability.test.status 1 "))
+	assert.True(t, strings.HasPrefix(lines[1], "e435d5ef-20da-4c61-928c-71bfbec9fa3e.test-capability.test.time 3 "))
+	assert.Empty(t, sender.buffer)
+	assert.Equal(t, uint64(0), sender.Dropped())
+}
+
+func TestGraphiteSenderDropsMetricsWhenBufferIsFull(t *testing.T) {
+	sender := newTestGraphiteSender(t, unusedAddress(t), config.GraphiteConfig{BufferSize: 3})
+
+	sender.Send(capabilityMetric(true))
+	sender.Send(capabilityMetric(false))
+
+	assert.Len(t, sender.buffer, 3)
+	assert.Equal(t, uint64(1), sender.Dropped())
+}
+
+func TestGraphiteSenderSpoolsOverflowingMetrics(t *testing.T) {
+	address := unusedAddress(t)
+	spoolPath := filepath.Join(t.TempDir(), "graphite", "spool")
+	gc := config.GraphiteConfig{BufferSize: 2, SpoolFilePath: spoolPath, SpoolMaxMetrics: 3}
+	sender := newTestGraphiteSender(t, address, gc)
+
+	sender.Send(capabilityMetric(true))
+	sender.Send(capabilityMetric(false))
+	sender.Send(capabilityMetric(false))
+
+	assert.Len(t, sender.buffer, 2)
+	assert.Equal(t, 3, sender.spool.count)
+	assert.Equal(t, uint64(1), sender.Dropped())
+
+	require.NoError(t, sender.Close())
+	sender = newTestGraphiteSender(t, address, gc)
+	assert.Equal(t, 3, sender.spool.count, "spooled metrics should survive a restart")
+
+	srv, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	sender.flush()
+	defer sender.Close()
+
+	lines := readGraphiteLines(t, srv, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "e435d5ef-20da-4c61-928c-71bfbec9fa3e.test-capability.test.status 0 "))
+	assert.Equal(t, 0, sender.spool.count)
+}
+
+func TestGraphiteSenderCloseSpoolsBufferedMetrics(t *testing.T) {
+	address := unusedAddress(t)
+	gc := config.GraphiteConfig{SpoolFilePath: filepath.Join(t.TempDir(), "spool")}
+	sender := newTestGraphiteSender(t, address, gc)
+	go sender.Run()
+
+	sender.Send(capabilityMetric(true))
+	require.NoError(t, sender.Close())
+
+	sender = newTestGraphiteSender(t, address, gc)
+	defer sender.Close()
+	assert.Equal(t, 2, sender.spool.count)
+}
+
+func TestGraphiteSenderUDP(t *testing.T) {
+	srv, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer srv.Close()
+
+	sender := newTestGraphiteSender(t, srv.LocalAddr().String(), config.GraphiteConfig{Protocol: GraphiteProtocolUDP})
+	defer sender.Close()
+
+	sender.Send(capabilityMetric(true))
+	sender.flush()
+
+	buf := make([]byte, graphiteMaxDatagramSize)
+	_ = srv.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := srv.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(buf[:n]), "\n"))
+}
+
+func TestGraphiteSenderSendGauges(t *testing.T) {
+	sender := newTestGraphiteSender(t, unusedAddress(t), config.GraphiteConfig{})
+	at := time.Unix(1700000000, 0)
+
+	sender.SendGauges([]Gauge{{Path: "publishes.staging-eu.content.p95", Value: 12}}, at)
+
+	assert.Equal(t, []graphiteMetric{
+		{Path: "e435d5ef-20da-4c61-928c-71bfbec9fa3e.publishes.staging-eu.content.p95", Value: 12, Timestamp: 1700000000},
+	}, sender.buffer)
+}
@@ -2,39 +2,142 @@ package metrics
 
 import (
 	"fmt"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+)
+
+const (
+	defaultHistorySize = 10
+	// recentPublishesCount is the number of latest publishes considered when looking for failures
+	recentPublishesCount = 10
 )
 
+// HistoryStore persists the publish history so that it survives restarts.
+type HistoryStore interface {
+	// Load returns the stored publish metrics, oldest first.
+	Load() ([]PublishMetric, error)
+	// Append stores a single publish metric.
+	Append(pm PublishMetric) error
+	// Rewrite replaces the stored publish metrics, discarding everything not in metrics.
+	Rewrite(metrics []PublishMetric) error
+	Close() error
+}
+
 type History struct {
 	mu             sync.RWMutex
 	PublishMetrics []PublishMetric
+	maxEntries     int
+	maxAge         time.Duration
+	store          HistoryStore
+	appended       int // metrics appended to the store since it was last rewritten
+	log            *logger.UPPLogger
 }
 
 func NewHistory(metrics []PublishMetric) *History {
 	return &History{
 		mu:             sync.RWMutex{},
 		PublishMetrics: metrics,
+		maxEntries:     defaultHistorySize,
 	}
 }
 
+// NewPersistentHistory returns a History backed by store, reloaded with the stored publish metrics
+// that are still within the retention limits.
+// A maxEntries of 0 defaults to the last 10 publishes, a maxAge of 0 disables age based retention.
+func NewPersistentHistory(store HistoryStore, maxEntries int, maxAge time.Duration, log *logger.UPPLogger) (*History, error) {
+	if maxEntries <= 0 {
+		maxEntries = defaultHistorySize
+	}
+
+	metrics, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load publish history: %w", err)
+	}
+
+	h := &History{
+		mu:             sync.RWMutex{},
+		PublishMetrics: metrics,
+		maxEntries:     maxEntries,
+		maxAge:         maxAge,
+		store:          store,
+		log:            log,
+	}
+	h.applyRetention()
+
+	// compact the store so that it holds only what was retained
+	if err = store.Rewrite(h.PublishMetrics); err != nil {
+		return nil, fmt.Errorf("cannot compact publish history: %w", err)
+	}
+
+	log.Infof("Loaded %d publish metrics into history", len(h.PublishMetrics))
+	return h, nil
+}
+
 func (h *History) Update(newPublishResult PublishMetric) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if len(h.PublishMetrics) == 10 {
-		h.PublishMetrics = h.PublishMetrics[1:len(h.PublishMetrics)]
-	}
 	h.PublishMetrics = append(h.PublishMetrics, newPublishResult)
+	h.applyRetention()
+
+	if h.store == nil {
+		return
+	}
+
+	if err := h.store.Append(newPublishResult); err != nil {
+		h.log.WithError(err).Error("Cannot persist publish metric to history")
+	}
+
+	// the store is append-only, so compact it once it has grown past the retained metrics
+	h.appended++
+	if h.appended < h.maxEntries {
+		return
+	}
+
+	if err := h.store.Rewrite(h.PublishMetrics); err != nil {
+		h.log.WithError(err).Error("Cannot compact publish history")
+		return
+	}
+	h.appended = 0
+}
+
+// applyRetention drops the metrics exceeding the configured count or age.
+// Callers must hold the write lock.
+func (h *History) applyRetention() {
+	start := 0
+	if len(h.PublishMetrics) > h.maxEntries {
+		start = len(h.PublishMetrics) - h.maxEntries
+	}
+
+	if h.maxAge > 0 {
+		earliest := time.Now().Add(-h.maxAge)
+		for start < len(h.PublishMetrics) && h.PublishMetrics[start].PublishDate.Before(earliest) {
+			start++
+		}
+	}
+
+	if start > 0 {
+		h.PublishMetrics = append([]PublishMetric(nil), h.PublishMetrics[start:]...)
+	}
 }
 
+// GetFailures returns the UUIDs which failed amongst the latest publishes.
 func (h *History) GetFailures() map[string]struct{} {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
+	start := 0
+	if len(h.PublishMetrics) > recentPublishesCount {
+		start = len(h.PublishMetrics) - recentPublishesCount
+	}
+
 	failures := make(map[string]struct{})
 	var emptyStruct struct{}
-	for i := 0; i < len(h.PublishMetrics); i++ {
-		if !h.PublishMetrics[i].PublishOK {
+	for i := start; i < len(h.PublishMetrics); i++ {
+		if h.PublishMetrics[i].GetOutcome() == OutcomeFailure {
 			failures[h.PublishMetrics[i].UUID] = emptyStruct
 		}
 	}
@@ -65,3 +168,110 @@ func (h *History) First() *PublishMetric {
 	defer h.mu.RUnlock()
 	return &h.PublishMetrics[0]
 }
+
+// Close releases the underlying store, if any.
+func (h *History) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.store == nil {
+		return nil
+	}
+	return h.store.Close()
+}
+
+// HistoryQuery describes which publish metrics to return from the history and in which order.
+// Zero values disable the corresponding filter.
+type HistoryQuery struct {
+	UUID          string
+	TID           string
+	Environment   string
+	EndpointAlias string
+	PublishOK     *bool
+	Outcome       Outcome
+	From          time.Time
+	To            time.Time
+	SortBy        string // one of the HistorySortBy* values, defaults to publish date
+	Ascending     bool
+	Offset        int
+	Limit         int // 0 returns every matching metric
+}
+
+const (
+	HistorySortByPublishDate = "publishDate"
+	HistorySortByDuration    = "duration"
+)
+
+// HistoryPage is a page of publish metrics matching a HistoryQuery.
+type HistoryPage struct {
+	Total          int             `json:"total"`
+	Offset         int             `json:"offset"`
+	Limit          int             `json:"limit"`
+	PublishMetrics []PublishMetric `json:"publishMetrics"`
+}
+
+func (q HistoryQuery) matches(pm PublishMetric) bool {
+	switch {
+	case q.UUID != "" && pm.UUID != q.UUID:
+		return false
+	case q.TID != "" && pm.TID != q.TID:
+		return false
+	case q.Environment != "" && pm.Platform != q.Environment:
+		return false
+	case q.EndpointAlias != "" && pm.Config.Alias != q.EndpointAlias:
+		return false
+	case q.PublishOK != nil && pm.PublishOK != *q.PublishOK:
+		return false
+	case q.Outcome != "" && pm.GetOutcome() != q.Outcome:
+		return false
+	case !q.From.IsZero() && pm.PublishDate.Before(q.From):
+		return false
+	case !q.To.IsZero() && pm.PublishDate.After(q.To):
+		return false
+	}
+	return true
+}
+
+// Query returns the page of publish metrics matching q.
+func (h *History) Query(q HistoryQuery) HistoryPage {
+	h.mu.RLock()
+	matching := make([]PublishMetric, 0)
+	for _, pm := range h.PublishMetrics {
+		if q.matches(pm) {
+			matching = append(matching, pm)
+		}
+	}
+	h.mu.RUnlock()
+
+	less := func(i, j int) bool {
+		return matching[i].PublishDate.Before(matching[j].PublishDate)
+	}
+	if q.SortBy == HistorySortByDuration {
+		less = func(i, j int) bool {
+			return matching[i].PublishInterval.UpperBound < matching[j].PublishInterval.UpperBound
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		if q.Ascending {
+			return less(i, j)
+		}
+		return less(j, i)
+	})
+
+	page := HistoryPage{
+		Total:          len(matching),
+		Offset:         q.Offset,
+		Limit:          q.Limit,
+		PublishMetrics: []PublishMetric{},
+	}
+	if q.Offset >= len(matching) {
+		return page
+	}
+
+	end := len(matching)
+	if q.Limit > 0 && q.Offset+q.Limit < end {
+		end = q.Offset + q.Limit
+	}
+	page.PublishMetrics = matching[q.Offset:end]
+	return page
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Financial-Times/publish-availability-monitor/jsonl"
+)
+
+// FileHistoryStore implements HistoryStore as an append-only log of JSON encoded
+// PublishMetrics, one per line.
+type FileHistoryStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileHistoryStore returns a FileHistoryStore writing to the file at path.
+// The file and its parent directories are created if missing.
+func NewFileHistoryStore(path string) (*FileHistoryStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create history directory: %w", err)
+	}
+
+	file, err := openAppendOnly(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileHistoryStore{path: path, file: file}, nil
+}
+
+func openAppendOnly(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open history file [%s]: %w", path, err)
+	}
+	return file, nil
+}
+
+// Load reads all the publish metrics from the file.
+// Lines which cannot be decoded, e.g. one left partially written by a crash, are skipped.
+func (s *FileHistoryStore) Load() ([]PublishMetric, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics, err := jsonl.ReadFile[PublishMetric](s.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load history: %w", err)
+	}
+	return metrics, nil
+}
+
+// Append writes pm at the end of the file.
+func (s *FileHistoryStore) Append(pm PublishMetric) error {
+	line, err := json.Marshal(pm)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Rewrite atomically replaces the file contents with metrics.
+func (s *FileHistoryStore) Rewrite(metrics []PublishMetric) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := jsonl.WriteFile(s.path, metrics); err != nil {
+		return fmt.Errorf("cannot rewrite history: %w", err)
+	}
+
+	// the old descriptor still points at the replaced file
+	_ = s.file.Close()
+	var err error
+	s.file, err = openAppendOnly(s.path)
+	return err
+}
+
+func (s *FileHistoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
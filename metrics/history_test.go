@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryUpdateKeepsLastTenPublishes(t *testing.T) {
+	history := NewHistory(make([]PublishMetric, 0))
+
+	for i := 0; i < 15; i++ {
+		history.Update(PublishMetric{UUID: string(rune('a' + i)), PublishOK: true})
+	}
+
+	assert.Equal(t, 10, history.Len())
+	assert.Equal(t, "f", history.First().UUID)
+}
+
+func TestHistoryGetFailuresConsidersOnlyLatestPublishes(t *testing.T) {
+	store := newTestFileHistoryStore(t)
+	log := logger.NewUPPLogger("test", "PANIC")
+
+	history, err := NewPersistentHistory(store, 50, 0, log)
+	require.NoError(t, err)
+
+	history.Update(PublishMetric{UUID: "old-failure", PublishOK: false})
+	for i := 0; i < 10; i++ {
+		history.Update(PublishMetric{UUID: "success", PublishOK: true})
+	}
+
+	assert.Equal(t, 11, history.Len())
+	assert.Empty(t, history.GetFailures())
+}
+
+func TestPersistentHistoryIsReloaded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "history.log")
+	log := logger.NewUPPLogger("test", "PANIC")
+
+	store, err := NewFileHistoryStore(path)
+	require.NoError(t, err)
+
+	history, err := NewPersistentHistory(store, 3, 0, log)
+	require.NoError(t, err)
+
+	endpoint, _ := url.Parse("http://localhost/content/")
+	for _, uuid := range []string{"1", "2", "3", "4", "5"} {
+		history.Update(PublishMetric{
+			UUID:        uuid,
+			TID:         "tid_" + uuid,
+			PublishDate: time.Now(),
+			Endpoint:    *endpoint,
+			Config:      config.MetricConfig{Alias: "content", APIKey: "secret"},
+		})
+	}
+	require.NoError(t, history.Close())
+
+	store, err = NewFileHistoryStore(path)
+	require.NoError(t, err)
+
+	reloaded, err := NewPersistentHistory(store, 3, 0, log)
+	require.NoError(t, err)
+	defer reloaded.Close()
+
+	require.Equal(t, 3, reloaded.Len())
+	first := reloaded.First()
+	assert.Equal(t, "3", first.UUID)
+	assert.Equal(t, "tid_3", first.TID)
+	assert.Equal(t, "content", first.Config.Alias)
+	assert.Equal(t, "http://localhost/content/", first.Endpoint.String())
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(contents), "secret", "API keys should not be persisted")
+}
+
+func TestPersistentHistoryDiscardsExpiredPublishes(t *testing.T) {
+	store := newTestFileHistoryStore(t)
+	log := logger.NewUPPLogger("test", "PANIC")
+
+	require.NoError(t, store.Append(PublishMetric{UUID: "expired", PublishDate: time.Now().Add(-3 * time.Hour)}))
+	require.NoError(t, store.Append(PublishMetric{UUID: "recent", PublishDate: time.Now().Add(-1 * time.Hour)}))
+
+	history, err := NewPersistentHistory(store, 10, 2*time.Hour, log)
+	require.NoError(t, err)
+	defer history.Close()
+
+	require.Equal(t, 1, history.Len())
+	assert.Equal(t, "recent", history.First().UUID)
+}
+
+func TestFileHistoryStoreSkipsCorruptedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.log")
+	err := os.WriteFile(path, []byte(`{"uuid":"1","endpoint":""}`+"\n"+`{"uuid":"2","endp`), 0o600)
+	require.NoError(t, err)
+
+	store, err := NewFileHistoryStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	metrics, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "1", metrics[0].UUID)
+}
+
+func newTestFileHistoryStore(t *testing.T) *FileHistoryStore {
+	store, err := NewFileHistoryStore(filepath.Join(t.TempDir(), "history.log"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+	return store
+}
+
+func TestHistoryQuery(t *testing.T) {
+	t0 := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	history := NewHistory(make([]PublishMetric, 0))
+	history.maxEntries = 100
+	for i, pm := range []PublishMetric{
+		{UUID: "1", TID: "tid_1", Platform: "eu", PublishOK: true, Config: config.MetricConfig{Alias: "content"}, PublishInterval: Interval{UpperBound: 9}},
+		{UUID: "1", TID: "tid_1", Platform: "us", PublishOK: false, Config: config.MetricConfig{Alias: "content"}, PublishInterval: Interval{UpperBound: 120}},
+		{UUID: "2", TID: "tid_2", Platform: "eu", PublishOK: true, Config: config.MetricConfig{Alias: "notifications"}, PublishInterval: Interval{UpperBound: 3}},
+		{UUID: "3", TID: "tid_3", Platform: "eu", PublishOK: true, Config: config.MetricConfig{Alias: "content"}, PublishInterval: Interval{UpperBound: 6}},
+	} {
+		pm.PublishDate = t0.Add(time.Duration(i) * time.Minute)
+		history.Update(pm)
+	}
+
+	failed := false
+	tests := map[string]struct {
+		Query         HistoryQuery
+		ExpectedTotal int
+		ExpectedTIDs  []string
+	}{
+		"no filters returns newest first": {
+			Query:         HistoryQuery{},
+			ExpectedTotal: 4,
+			ExpectedTIDs:  []string{"tid_3", "tid_2", "tid_1", "tid_1"},
+		},
+		"filter by uuid and environment": {
+			Query:         HistoryQuery{UUID: "1", Environment: "us"},
+			ExpectedTotal: 1,
+			ExpectedTIDs:  []string{"tid_1"},
+		},
+		"filter by endpoint and publish status": {
+			Query:         HistoryQuery{EndpointAlias: "content", PublishOK: &failed},
+			ExpectedTotal: 1,
+			ExpectedTIDs:  []string{"tid_1"},
+		},
+		"filter by outcome": {
+			Query:         HistoryQuery{Outcome: OutcomeFailure},
+			ExpectedTotal: 1,
+			ExpectedTIDs:  []string{"tid_1"},
+		},
+		"filter by time range": {
+			Query:         HistoryQuery{From: t0.Add(time.Minute), To: t0.Add(2 * time.Minute)},
+			ExpectedTotal: 2,
+			ExpectedTIDs:  []string{"tid_2", "tid_1"},
+		},
+		"sort by duration ascending": {
+			Query:         HistoryQuery{SortBy: HistorySortByDuration, Ascending: true},
+			ExpectedTotal: 4,
+			ExpectedTIDs:  []string{"tid_2", "tid_3", "tid_1", "tid_1"},
+		},
+		"paginate": {
+			Query:         HistoryQuery{Offset: 1, Limit: 2},
+			ExpectedTotal: 4,
+			ExpectedTIDs:  []string{"tid_2", "tid_1"},
+		},
+		"offset past the end": {
+			Query:         HistoryQuery{Offset: 10, Limit: 2},
+			ExpectedTotal: 4,
+			ExpectedTIDs:  []string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			page := history.Query(test.Query)
+
+			tids := make([]string, 0)
+			for _, pm := range page.PublishMetrics {
+				tids = append(tids, pm.TID)
+			}
+			assert.Equal(t, test.ExpectedTotal, page.Total)
+			assert.Equal(t, test.ExpectedTIDs, tids)
+		})
+	}
+}
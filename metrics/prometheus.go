@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const prometheusNamespace = "publish_availability_monitor"
+
+// publishDurationBuckets cover the usual check intervals up to a few times the common 120 seconds SLA.
+var publishDurationBuckets = []float64{1, 2, 5, 10, 15, 20, 30, 45, 60, 90, 120, 180, 300, 600}
+
+// PrometheusDestination implements Destination interface to expose PublishMetrics to Prometheus.
+// It keeps its own registry, which is served by Handler.
+type PrometheusDestination struct {
+	registry        *prometheus.Registry
+	publishDuration *prometheus.HistogramVec
+	publishLatency  *prometheus.HistogramVec
+	publishLateness *prometheus.HistogramVec
+	checks          *prometheus.CounterVec
+	inconclusive    *prometheus.CounterVec
+}
+
+// NewPrometheusDestination returns a PrometheusDestination with the publish metrics
+// and the Go runtime and process collectors registered.
+func NewPrometheusDestination() *PrometheusDestination {
+	labels := []string{"endpoint", "environment", "content_type", "capability"}
+
+	pd := &PrometheusDestination{
+		registry: prometheus.NewRegistry(),
+		publishDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Name:      "publish_duration_seconds",
+			Help:      "Upper bound of the check interval in which successful publishes became available.",
+			Buckets:   publishDurationBuckets,
+		}, labels),
+		publishLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Name:      "publish_latency_seconds",
+			Help:      "Time between the publish and when successful publishes were first seen, when it was recorded.",
+			Buckets:   publishDurationBuckets,
+		}, labels),
+		publishLateness: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Name:      "publish_lateness_seconds",
+			Help:      "Time between the SLA and when late publishes became available.",
+			Buckets:   publishDurationBuckets,
+		}, labels),
+		checks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Name:      "checks_total",
+			Help:      "Number of completed publish checks by outcome.",
+		}, append(labels, "outcome")),
+		inconclusive: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Name:      "inconclusive_checks_total",
+			Help:      "Number of publish checks which could not tell whether the publish succeeded, by reason.",
+		}, append(labels, "reason")),
+	}
+
+	pd.registry.MustRegister(
+		pd.publishDuration,
+		pd.publishLatency,
+		pd.publishLateness,
+		pd.checks,
+		pd.inconclusive,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return pd
+}
+
+// Send records pm in the checks counter and, if the publish succeeded, its duration and,
+// when it was recorded, its latency in the histograms. The lateness of late publishes
+// and the reason of inconclusive checks are recorded as well.
+func (pd *PrometheusDestination) Send(pm PublishMetric) {
+	var capability string
+	if pm.Capability != nil {
+		capability = pm.Capability.Name
+	}
+	labels := prometheus.Labels{
+		"endpoint":     pm.Config.Alias,
+		"environment":  pm.Platform,
+		"content_type": pm.ContentType,
+		"capability":   capability,
+	}
+
+	outcome := pm.GetOutcome()
+	if outcome == OutcomeSuccess {
+		pd.publishDuration.With(labels).Observe(float64(pm.PublishInterval.UpperBound))
+		if latency, found := pm.Latency(); found {
+			pd.publishLatency.With(labels).Observe(latency.Seconds())
+		}
+	}
+	if outcome == OutcomeLate {
+		pd.publishLateness.With(labels).Observe(pm.Lateness.Seconds())
+	}
+	if outcome == OutcomeInconclusive {
+		reasonLabels := prometheus.Labels{"reason": string(pm.Reason)}
+		for k, v := range labels {
+			reasonLabels[k] = v
+		}
+		pd.inconclusive.With(reasonLabels).Inc()
+	}
+
+	labels["outcome"] = string(outcome)
+	pd.checks.With(labels).Inc()
+}
+
+// RegisterDroppedMetrics exposes the number of metrics the given destination had to drop.
+func (pd *PrometheusDestination) RegisterDroppedMetrics(destination string, dropped func() uint64) {
+	pd.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   prometheusNamespace,
+		Name:        "destination_dropped_metrics_total",
+		Help:        "Number of metrics a destination dropped because it could not keep up.",
+		ConstLabels: prometheus.Labels{"destination": destination},
+	}, func() float64 {
+		return float64(dropped())
+	}))
+}
+
+// CheckQueueStats reports the state of the queue of scheduled checks.
+type CheckQueueStats struct {
+	Queued  func() int // checks waiting for their next attempt
+	Due     func() int // checks due and waiting for a free worker
+	Running func() int // checks being attempted
+	Blocked func() int // publishes held back waiting for room to schedule their checks
+}
+
+// RegisterCheckQueue exposes the depth of the queue of scheduled checks.
+func (pd *PrometheusDestination) RegisterCheckQueue(stats CheckQueueStats) {
+	gauges := []struct {
+		name  string
+		help  string
+		value func() int
+	}{
+		{"scheduled_checks", "Number of checks waiting for their next attempt.", stats.Queued},
+		{"due_checks", "Number of checks due and waiting for a free worker.", stats.Due},
+		{"running_checks", "Number of checks being attempted.", stats.Running},
+		{"blocked_publishes", "Number of publishes held back waiting for room to schedule their checks.", stats.Blocked},
+	}
+	for _, g := range gauges {
+		value := g.value
+		pd.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Name:      g.name,
+			Help:      g.help,
+		}, func() float64 {
+			return float64(value())
+		}))
+	}
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (pd *PrometheusDestination) Handler() http.Handler {
+	return promhttp.HandlerFor(pd.registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusDestinationSend(t *testing.T) {
+	pd := NewPrometheusDestination()
+
+	pm := PublishMetric{
+		ContentType:     "application/vnd.ft-upp-article+json",
+		Platform:        "eu",
+		Config:          config.MetricConfig{Alias: "content"},
+		PublishInterval: Interval{LowerBound: 5, UpperBound: 10},
+	}
+
+	success := pm
+	success.PublishOK = true
+	success.Outcome = OutcomeSuccess
+	pd.Send(success)
+	pd.Send(success)
+
+	failure := pm
+	failure.Outcome = OutcomeFailure
+	pd.Send(failure)
+
+	ignored := pm
+	ignored.Outcome = OutcomeIgnored
+	pd.Send(ignored)
+
+	capability := success
+	capability.Capability = &config.Capability{Name: "article-publish"}
+	pd.Send(capability)
+
+	labels := prometheus.Labels{
+		"endpoint":     "content",
+		"environment":  "eu",
+		"content_type": "application/vnd.ft-upp-article+json",
+		"capability":   "",
+	}
+	for outcome, expected := range map[Outcome]float64{OutcomeSuccess: 2, OutcomeFailure: 1, OutcomeIgnored: 1} {
+		labels["outcome"] = string(outcome)
+		assert.Equal(t, expected, testutil.ToFloat64(pd.checks.With(labels)), "outcome %s", outcome)
+	}
+
+	labels["capability"] = "article-publish"
+	labels["outcome"] = string(OutcomeSuccess)
+	assert.Equal(t, float64(1), testutil.ToFloat64(pd.checks.With(labels)))
+
+	assert.Equal(t, 2, testutil.CollectAndCount(pd.publishDuration), "only successful publishes should be observed")
+	assert.Equal(t, 0, testutil.CollectAndCount(pd.publishLatency), "latency should be observed only when recorded")
+}
+
+func TestPrometheusDestinationObservesLatency(t *testing.T) {
+	pd := NewPrometheusDestination()
+
+	publishDate := time.Now().Add(-time.Minute)
+	pd.Send(PublishMetric{
+		Platform:    "eu",
+		Config:      config.MetricConfig{Alias: "content"},
+		PublishOK:   true,
+		PublishDate: publishDate,
+		Observation: Observation{ObservedAt: publishDate.Add(3 * time.Second)},
+	})
+
+	assert.Equal(t, 1, testutil.CollectAndCount(pd.publishLatency))
+}
+
+func TestPrometheusDestinationObservesLateness(t *testing.T) {
+	pd := NewPrometheusDestination()
+
+	pm := PublishMetric{
+		Platform:        "eu",
+		Config:          config.MetricConfig{Alias: "content"},
+		PublishInterval: Interval{LowerBound: 120, UpperBound: 150},
+	}
+	late := pm
+	late.PublishOK = true
+	late.Outcome = OutcomeLate
+	late.Lateness = 20 * time.Second
+	pd.Send(late)
+	lost := pm
+	lost.Outcome = OutcomeLost
+	pd.Send(lost)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(pd.publishLateness))
+	assert.Equal(t, 0, testutil.CollectAndCount(pd.publishDuration), "late publishes should not be observed as successful")
+	labels := prometheus.Labels{"endpoint": "content", "environment": "eu", "content_type": "", "capability": ""}
+	for _, outcome := range []Outcome{OutcomeLate, OutcomeLost} {
+		labels["outcome"] = string(outcome)
+		assert.Equal(t, float64(1), testutil.ToFloat64(pd.checks.With(labels)), "outcome %s", outcome)
+	}
+}
+
+func TestPrometheusDestinationCountsInconclusiveReasons(t *testing.T) {
+	pd := NewPrometheusDestination()
+
+	pd.Send(PublishMetric{Platform: "eu", Config: config.MetricConfig{Alias: "content"}, Outcome: OutcomeInconclusive, Reason: ReasonTimeout})
+	pd.Send(PublishMetric{Platform: "eu", Config: config.MetricConfig{Alias: "content"}, Outcome: OutcomeFailure})
+
+	assert.Equal(t, 1, testutil.CollectAndCount(pd.inconclusive))
+	assert.Equal(t, float64(1), testutil.ToFloat64(pd.inconclusive.With(prometheus.Labels{
+		"endpoint":     "content",
+		"environment":  "eu",
+		"content_type": "",
+		"capability":   "",
+		"reason":       string(ReasonTimeout),
+	})))
+}
+
+func TestPrometheusDestinationOutcomeFallsBackToPublishOK(t *testing.T) {
+	pd := NewPrometheusDestination()
+
+	pd.Send(PublishMetric{Platform: "eu", Config: config.MetricConfig{Alias: "content"}, PublishOK: true})
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(pd.checks.With(prometheus.Labels{
+		"endpoint":     "content",
+		"environment":  "eu",
+		"content_type": "",
+		"capability":   "",
+		"outcome":      string(OutcomeSuccess),
+	})))
+}
+
+func TestPrometheusDestinationHandler(t *testing.T) {
+	pd := NewPrometheusDestination()
+	pd.Send(PublishMetric{
+		Platform:        "eu",
+		Config:          config.MetricConfig{Alias: "content"},
+		PublishOK:       true,
+		Outcome:         OutcomeSuccess,
+		PublishInterval: Interval{UpperBound: 10},
+	})
+
+	w := httptest.NewRecorder()
+	pd.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.True(t, strings.Contains(body, `publish_availability_monitor_checks_total{capability="",content_type="",endpoint="content",environment="eu",outcome="success"} 1`))
+	assert.True(t, strings.Contains(body, `publish_availability_monitor_publish_duration_seconds_bucket{capability="",content_type="",endpoint="content",environment="eu",le="10"} 1`))
+	assert.True(t, strings.Contains(body, "go_goroutines"))
+}
+
+func TestPrometheusDestinationCheckQueue(t *testing.T) {
+	pd := NewPrometheusDestination()
+	pd.RegisterCheckQueue(CheckQueueStats{
+		Queued:  func() int { return 12 },
+		Due:     func() int { return 3 },
+		Running: func() int { return 2 },
+		Blocked: func() int { return 1 },
+	})
+
+	w := httptest.NewRecorder()
+	pd.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := w.Body.String()
+	assert.Contains(t, body, "publish_availability_monitor_scheduled_checks 12")
+	assert.Contains(t, body, "publish_availability_monitor_due_checks 3")
+	assert.Contains(t, body, "publish_availability_monitor_running_checks 2")
+	assert.Contains(t, body, "publish_availability_monitor_blocked_publishes 1")
+}
@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+)
+
+const (
+	defaultPublishStatsWindow         = 15 * time.Minute
+	defaultPublishStatsReportInterval = time.Minute
+	defaultPublishStatsPrefix         = "publishes"
+)
+
+// Gauge is a value reported as is, ex. to Graphite or StatsD.
+type Gauge struct {
+	Path  string
+	Value float64
+}
+
+// GaugeSink is the interface which defines a method to report gauges.
+type GaugeSink interface {
+	SendGauges(gauges []Gauge, at time.Time)
+}
+
+type publishStatsKey struct {
+	alias       string
+	environment string
+}
+
+type publishSample struct {
+	at       time.Time
+	ok       bool
+	duration time.Duration // latency of the publish
+}
+
+// PublishStats implements Destination interface to aggregate the regular publish metrics
+// per endpoint alias and environment over a rolling window.
+// The success ratio, p50/p95 durations and count are reported to a GaugeSink by Run.
+type PublishStats struct {
+	window         time.Duration
+	reportInterval time.Duration
+	prefix         string
+	sink           GaugeSink
+	now            func() time.Time
+	mu             sync.Mutex
+	samples        map[publishStatsKey][]publishSample
+	stop           chan struct{}
+	stopOnce       sync.Once
+}
+
+// NewPublishStats returns a PublishStats reporting to sink.
+func NewPublishStats(cfg config.StatsConfig, sink GaugeSink) *PublishStats {
+	ps := &PublishStats{
+		window:         time.Duration(cfg.WindowMinutes) * time.Minute,
+		reportInterval: time.Duration(cfg.ReportIntervalSeconds) * time.Second,
+		prefix:         cfg.Prefix,
+		sink:           sink,
+		now:            time.Now,
+		samples:        make(map[publishStatsKey][]publishSample),
+		stop:           make(chan struct{}),
+	}
+	if ps.window <= 0 {
+		ps.window = defaultPublishStatsWindow
+	}
+	if ps.reportInterval <= 0 {
+		ps.reportInterval = defaultPublishStatsReportInterval
+	}
+	if ps.prefix == "" {
+		ps.prefix = defaultPublishStatsPrefix
+	}
+	return ps
+}
+
+// Send adds pm to the window of its endpoint and environment. Capability metrics and the ones which are not SLA outcomes are skipped.
+func (ps *PublishStats) Send(pm PublishMetric) {
+	if pm.Capability != nil || !pm.GetOutcome().IsSLAOutcome() {
+		return
+	}
+
+	// without a recorded latency, the publish was available by the upper bound of its publish interval
+	duration, found := pm.Latency()
+	if !found {
+		duration = time.Duration(pm.PublishInterval.UpperBound) * time.Second
+	}
+
+	key := publishStatsKey{alias: pm.Config.Alias, environment: pm.Platform}
+	sample := publishSample{at: ps.now(), ok: pm.PublishOK, duration: duration}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.samples[key] = append(ps.samples[key], sample)
+}
+
+// Run reports the stats every report interval until Stop is called.
+func (ps *PublishStats) Run() {
+	ticker := time.NewTicker(ps.reportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ps.stop:
+			return
+		case <-ticker.C:
+			now := ps.now()
+			ps.sink.SendGauges(ps.Gauges(now), now)
+		}
+	}
+}
+
+// Stop stops Run.
+func (ps *PublishStats) Stop() {
+	ps.stopOnce.Do(func() {
+		close(ps.stop)
+	})
+}
+
+// Gauges discards the samples older than the window and returns the stats of the remaining ones,
+// as <prefix>.<environment>.<alias>.<stat> gauges.
+// The p50 and p95 durations are only reported if a publish succeeded within the window.
+func (ps *PublishStats) Gauges(now time.Time) []Gauge {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	keys := make([]publishStatsKey, 0, len(ps.samples))
+	for key, samples := range ps.samples {
+		i := sort.Search(len(samples), func(i int) bool {
+			return now.Sub(samples[i].at) < ps.window
+		})
+		if i == len(samples) {
+			delete(ps.samples, key)
+			continue
+		}
+		ps.samples[key] = samples[i:]
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].environment != keys[j].environment {
+			return keys[i].environment < keys[j].environment
+		}
+		return keys[i].alias < keys[j].alias
+	})
+
+	var gauges []Gauge
+	for _, key := range keys {
+		samples := ps.samples[key]
+		prefix := fmt.Sprintf("%s.%s.%s.", ps.prefix, sanitiseGaugePathNode(key.environment), sanitiseGaugePathNode(key.alias))
+
+		var durations []time.Duration
+		for _, s := range samples {
+			if s.ok {
+				durations = append(durations, s.duration)
+			}
+		}
+
+		gauges = append(gauges,
+			Gauge{Path: prefix + "count", Value: float64(len(samples))},
+			Gauge{Path: prefix + "success_ratio", Value: float64(len(durations)) / float64(len(samples))},
+		)
+		if len(durations) > 0 {
+			sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+			gauges = append(gauges,
+				Gauge{Path: prefix + "p50", Value: percentile(durations, 50).Seconds()},
+				Gauge{Path: prefix + "p95", Value: percentile(durations, 95).Seconds()},
+			)
+		}
+	}
+	return gauges
+}
+
+// percentile returns the nearest-rank percentile p of the sorted values.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// sanitiseGaugePathNode replaces the characters separating the nodes of metric paths.
+func sanitiseGaugePathNode(node string) string {
+	if node == "" {
+		return "unknown"
+	}
+	return strings.NewReplacer(".", "_", " ", "_", ":", "_", "|", "_", "/", "_").Replace(node)
+}
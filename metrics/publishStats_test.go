@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockGaugeSink struct {
+	mu     sync.Mutex
+	gauges []Gauge
+}
+
+func (m *mockGaugeSink) SendGauges(gauges []Gauge, _ time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gauges = append(m.gauges, gauges...)
+}
+
+func (m *mockGaugeSink) received() []Gauge {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Gauge(nil), m.gauges...)
+}
+
+func statsMetric(alias, environment string, outcome Outcome, duration int) PublishMetric {
+	return PublishMetric{
+		PublishOK:       outcome == OutcomeSuccess,
+		Outcome:         outcome,
+		Platform:        environment,
+		Config:          config.MetricConfig{Alias: alias},
+		PublishInterval: Interval{UpperBound: duration},
+	}
+}
+
+func TestPublishStatsGauges(t *testing.T) {
+	ps := NewPublishStats(config.StatsConfig{}, &mockGaugeSink{})
+	now := time.Now()
+	ps.now = func() time.Time { return now }
+
+	for duration := 1; duration <= 19; duration++ {
+		ps.Send(statsMetric("content", "staging-eu", OutcomeSuccess, duration))
+	}
+	ps.Send(statsMetric("content", "staging-eu", OutcomeFailure, 120))
+	ps.Send(statsMetric("content", "staging-eu", OutcomeIgnored, 3))
+	ps.Send(statsMetric("lists", "staging-eu", OutcomeFailure, 120))
+
+	capability := statsMetric("content", "staging-eu", OutcomeSuccess, 3)
+	capability.Capability = &config.Capability{Name: "article-publish"}
+	ps.Send(capability)
+
+	assert.Equal(t, []Gauge{
+		{Path: "publishes.staging-eu.content.count", Value: 20},
+		{Path: "publishes.staging-eu.content.success_ratio", Value: 0.95},
+		{Path: "publishes.staging-eu.content.p50", Value: 10},
+		{Path: "publishes.staging-eu.content.p95", Value: 19},
+		{Path: "publishes.staging-eu.lists.count", Value: 1},
+		{Path: "publishes.staging-eu.lists.success_ratio", Value: 0},
+	}, ps.Gauges(now))
+}
+
+func TestPublishStatsGaugesOfRecordedLatencies(t *testing.T) {
+	ps := NewPublishStats(config.StatsConfig{}, &mockGaugeSink{})
+	now := time.Now()
+	ps.now = func() time.Time { return now }
+
+	// the publishes were available within the buckets of the polling
+	publishDate := now.Add(-time.Minute)
+	for _, latency := range []time.Duration{1200, 2500, 2700, 4100} {
+		pm := statsMetric("content", "staging-eu", OutcomeSuccess, 5)
+		pm.PublishDate = publishDate
+		pm.ObservedAt = publishDate.Add(latency * time.Millisecond)
+		ps.Send(pm)
+	}
+
+	assert.Equal(t, []Gauge{
+		{Path: "publishes.staging-eu.content.count", Value: 4},
+		{Path: "publishes.staging-eu.content.success_ratio", Value: 1},
+		{Path: "publishes.staging-eu.content.p50", Value: 2.5},
+		{Path: "publishes.staging-eu.content.p95", Value: 4.1},
+	}, ps.Gauges(now))
+}
+
+func TestPublishStatsRollingWindow(t *testing.T) {
+	ps := NewPublishStats(config.StatsConfig{WindowMinutes: 5, Prefix: "pam"}, &mockGaugeSink{})
+	start := time.Now()
+	now := start
+	ps.now = func() time.Time { return now }
+
+	ps.Send(statsMetric("content", "prod.eu", OutcomeFailure, 120))
+	now = start.Add(3 * time.Minute)
+	ps.Send(statsMetric("content", "prod.eu", OutcomeSuccess, 6))
+
+	assert.Equal(t, []Gauge{
+		{Path: "pam.prod_eu.content.count", Value: 2},
+		{Path: "pam.prod_eu.content.success_ratio", Value: 0.5},
+		{Path: "pam.prod_eu.content.p50", Value: 6},
+		{Path: "pam.prod_eu.content.p95", Value: 6},
+	}, ps.Gauges(start.Add(4*time.Minute)))
+
+	assert.Equal(t, []Gauge{
+		{Path: "pam.prod_eu.content.count", Value: 1},
+		{Path: "pam.prod_eu.content.success_ratio", Value: 1},
+		{Path: "pam.prod_eu.content.p50", Value: 6},
+		{Path: "pam.prod_eu.content.p95", Value: 6},
+	}, ps.Gauges(start.Add(6*time.Minute)))
+
+	assert.Empty(t, ps.Gauges(start.Add(9*time.Minute)))
+	assert.Empty(t, ps.samples)
+}
+
+func TestPublishStatsRun(t *testing.T) {
+	sink := &mockGaugeSink{}
+	ps := NewPublishStats(config.StatsConfig{}, sink)
+	ps.reportInterval = 10 * time.Millisecond
+	ps.Send(statsMetric("content", "staging-eu", OutcomeSuccess, 3))
+
+	go ps.Run()
+	defer ps.Stop()
+
+	require.Eventually(t, func() bool {
+		return len(sink.received()) > 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, Gauge{Path: "publishes.staging-eu.content.count", Value: 1}, sink.received()[0])
+}
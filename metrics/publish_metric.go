@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"time"
@@ -8,11 +9,51 @@ import (
 	"github.com/Financial-Times/publish-availability-monitor/config"
 )
 
+// Outcome is the result of checking a publish at an endpoint.
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+	OutcomeIgnored Outcome = "ignored" // the endpoint check decided this publish should not be monitored
+	// the monitor could not check the endpoint by the SLA, so whether the publish failed is unknown,
+	// the Reason of the metric tells why
+	OutcomeInconclusive Outcome = "inconclusive"
+	// outcomes of late tracking, reported after the failure of the same check
+	OutcomeLate Outcome = "late" // the content became available after the SLA
+	OutcomeLost Outcome = "lost" // the content was not available by the late tracking hard limit
+	// the monitor stopped before the check could finish, so whether the publish failed is unknown
+	OutcomeInterrupted Outcome = "interrupted"
+)
+
+// IsSLAOutcome reports whether o tells whether a publish met the SLA, that is whether it is a success or a failure.
+func (o Outcome) IsSLAOutcome() bool {
+	return o == OutcomeSuccess || o == OutcomeFailure
+}
+
+// IsLateTracking reports whether o is reported by late tracking, on top of the SLA outcome of a check.
+func (o Outcome) IsLateTracking() bool {
+	return o == OutcomeLate || o == OutcomeLost
+}
+
+// InconclusiveReason tells why the monitor could not check an endpoint.
+type InconclusiveReason string
+
+const (
+	ReasonTimeout     InconclusiveReason = "timeout"     // the call to the endpoint timed out
+	ReasonUnreachable InconclusiveReason = "unreachable" // the call to the endpoint failed, ex. the connection was refused
+	ReasonServerError InconclusiveReason = "5xx"         // the endpoint responded with a server error
+	ReasonAuth        InconclusiveReason = "auth"        // the endpoint did not accept the credentials of the monitor
+	ReasonUnparseable InconclusiveReason = "unparseable" // the response of the endpoint could not be parsed
+	ReasonCircuitOpen InconclusiveReason = "circuitOpen" // the call was not made as the circuit of the endpoint was open
+)
+
 // PublishMetric holds the information about the metric we are measuring.
 type PublishMetric struct {
 	UUID            string
 	EditorialDesk   string
 	Publication     []string
+	ContentType     string
 	PublishOK       bool      // did it meet the SLA?
 	PublishDate     time.Time // the time WE get the message
 	Platform        string
@@ -22,6 +63,197 @@ type PublishMetric struct {
 	TID             string
 	IsMarkedDeleted bool
 	Capability      *config.Capability
+	Outcome         Outcome
+	Reason          InconclusiveReason // why the endpoint could not be checked, for the inconclusive outcome only
+	Lateness        time.Duration      // how long after the SLA late publishes became available
+	Attempts        []CheckAttempt     // the last attempts of the check, for the failures only
+	Observation                        // precise times the publish was seen, on top of the interval
+}
+
+// CheckAttempt describes an attempt of a check, to tell why a publish failed.
+type CheckAttempt struct {
+	At               time.Time     `json:"at"`
+	URL              string        `json:"url,omitempty"`
+	StatusCode       int           `json:"statusCode,omitempty"`
+	PublishReference string        `json:"publishReference,omitempty"` // of the content or notification found
+	LastModified     string        `json:"lastModified,omitempty"`     // of the content or notification found
+	Latency          time.Duration `json:"latency"`                    // nanoseconds
+	Error            string        `json:"error,omitempty"`
+}
+
+// Observation holds the precise times a publish was seen at an endpoint, zero if unknown.
+type Observation struct {
+	ObservedAt               time.Time // wall-clock time of the first successful check
+	NotificationLastModified time.Time // lastModified of the notification found by the check
+	NotificationReceivedAt   time.Time // when the notification was received from the feed
+}
+
+// publishMetricJSON is the serialised form of a PublishMetric.
+// The endpoint is kept as a plain URL and the metric config API key is never written out.
+type publishMetricJSON struct {
+	UUID            string              `json:"uuid"`
+	EditorialDesk   string              `json:"editorialDesk,omitempty"`
+	Publication     []string            `json:"publication,omitempty"`
+	ContentType     string              `json:"contentType,omitempty"`
+	PublishOK       bool                `json:"publishOk"`
+	Outcome         Outcome             `json:"outcome,omitempty"`
+	Reason          InconclusiveReason  `json:"inconclusiveReason,omitempty"`
+	PublishDate     time.Time           `json:"publishDate"`
+	Platform        string              `json:"platform"`
+	PublishInterval Interval            `json:"publishInterval"`
+	Config          config.MetricConfig `json:"config"`
+	Endpoint        string              `json:"endpoint"`
+	TID             string              `json:"transactionId"`
+	IsMarkedDeleted bool                `json:"isMarkedDeleted"`
+	Capability      *config.Capability  `json:"capability,omitempty"`
+	Lateness        time.Duration       `json:"lateness,omitempty"` // nanoseconds
+	Attempts        []CheckAttempt      `json:"attempts,omitempty"`
+	Diagnosis       string              `json:"diagnosis,omitempty"` // derived from the attempts, not read back
+
+	ObservedAt               *time.Time `json:"observedAt,omitempty"`
+	NotificationLastModified *time.Time `json:"notificationLastModified,omitempty"`
+	NotificationReceivedAt   *time.Time `json:"notificationReceivedAt,omitempty"`
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func (pm PublishMetric) MarshalJSON() ([]byte, error) {
+	cfg := pm.Config
+	cfg.APIKey = ""
+
+	return json.Marshal(publishMetricJSON{
+		UUID:            pm.UUID,
+		EditorialDesk:   pm.EditorialDesk,
+		Publication:     pm.Publication,
+		ContentType:     pm.ContentType,
+		PublishOK:       pm.PublishOK,
+		Outcome:         pm.Outcome,
+		Reason:          pm.Reason,
+		PublishDate:     pm.PublishDate,
+		Platform:        pm.Platform,
+		PublishInterval: pm.PublishInterval,
+		Config:          cfg,
+		Endpoint:        pm.Endpoint.String(),
+		TID:             pm.TID,
+		IsMarkedDeleted: pm.IsMarkedDeleted,
+		Capability:      pm.Capability,
+		Lateness:        pm.Lateness,
+		Attempts:        pm.Attempts,
+		Diagnosis:       pm.Diagnosis(),
+
+		ObservedAt:               timeOrNil(pm.ObservedAt),
+		NotificationLastModified: timeOrNil(pm.NotificationLastModified),
+		NotificationReceivedAt:   timeOrNil(pm.NotificationReceivedAt),
+	})
+}
+
+func (pm *PublishMetric) UnmarshalJSON(data []byte) error {
+	var aux publishMetricJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(aux.Endpoint)
+	if err != nil {
+		return fmt.Errorf("cannot parse endpoint [%s]: %w", aux.Endpoint, err)
+	}
+
+	*pm = PublishMetric{
+		UUID:            aux.UUID,
+		EditorialDesk:   aux.EditorialDesk,
+		Publication:     aux.Publication,
+		ContentType:     aux.ContentType,
+		PublishOK:       aux.PublishOK,
+		Outcome:         aux.Outcome,
+		Reason:          aux.Reason,
+		PublishDate:     aux.PublishDate,
+		Platform:        aux.Platform,
+		PublishInterval: aux.PublishInterval,
+		Config:          aux.Config,
+		Endpoint:        *endpoint,
+		TID:             aux.TID,
+		IsMarkedDeleted: aux.IsMarkedDeleted,
+		Capability:      aux.Capability,
+		Lateness:        aux.Lateness,
+		Attempts:        aux.Attempts,
+		Observation: Observation{
+			ObservedAt:               timeOrZero(aux.ObservedAt),
+			NotificationLastModified: timeOrZero(aux.NotificationLastModified),
+			NotificationReceivedAt:   timeOrZero(aux.NotificationReceivedAt),
+		},
+	}
+	return nil
+}
+
+// GetOutcome returns the outcome of the check, falling back to PublishOK
+// for metrics recorded before outcomes were introduced.
+func (pm PublishMetric) GetOutcome() Outcome {
+	if pm.Outcome != "" {
+		return pm.Outcome
+	}
+	if pm.PublishOK {
+		return OutcomeSuccess
+	}
+	return OutcomeFailure
+}
+
+// Diagnosis summarises why the check failed from its last attempts, or returns an empty string if they were not recorded.
+func (pm PublishMetric) Diagnosis() string {
+	if len(pm.Attempts) == 0 {
+		return ""
+	}
+
+	last := pm.Attempts[len(pm.Attempts)-1]
+	if last.Error != "" {
+		return fmt.Sprintf("the last attempt failed: %s", last.Error)
+	}
+
+	sameStatusCode, samePublishReference := true, true
+	for _, a := range pm.Attempts {
+		sameStatusCode = sameStatusCode && a.StatusCode == last.StatusCode
+		samePublishReference = samePublishReference && a.PublishReference == last.PublishReference
+	}
+	switch {
+	case last.PublishReference != "" && last.PublishReference != pm.TID && samePublishReference:
+		return fmt.Sprintf("the endpoint kept returning the previous publishReference [%s]", last.PublishReference)
+	case last.PublishReference != "" && last.PublishReference != pm.TID:
+		return fmt.Sprintf("the endpoint last returned the publishReference [%s]", last.PublishReference)
+	case last.StatusCode != 0 && sameStatusCode:
+		return fmt.Sprintf("the endpoint kept responding with status code %d", last.StatusCode)
+	case last.StatusCode != 0:
+		return fmt.Sprintf("the endpoint last responded with status code %d", last.StatusCode)
+	}
+	return "the publish was never found at the endpoint"
+}
+
+// Latency returns how long after the publish date the content was first seen at the endpoint,
+// preferring the time the notification was received for notification endpoints,
+// and false if no precise time was recorded.
+func (pm PublishMetric) Latency() (time.Duration, bool) {
+	seenAt := pm.NotificationReceivedAt
+	if seenAt.IsZero() {
+		seenAt = pm.ObservedAt
+	}
+	if seenAt.IsZero() || pm.PublishDate.IsZero() {
+		return 0, false
+	}
+	latency := seenAt.Sub(pm.PublishDate)
+	if latency < 0 {
+		latency = 0 // clocks are not perfectly in sync
+	}
+	return latency, true
 }
 
 func (pm PublishMetric) String() string {
@@ -42,6 +274,6 @@ func (pm PublishMetric) String() string {
 // Interval is a simple representation of an interval of time, with a lower and
 // upper boundary
 type Interval struct {
-	LowerBound int
-	UpperBound int
+	LowerBound int `json:"lowerBound"`
+	UpperBound int `json:"upperBound"`
 }
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishMetricLatency(t *testing.T) {
+	publishDate := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		Observation     Observation
+		ExpectedLatency time.Duration
+		ExpectedFound   bool
+	}{
+		"no observation": {},
+		"observed by a check": {
+			Observation:     Observation{ObservedAt: publishDate.Add(7 * time.Second)},
+			ExpectedLatency: 7 * time.Second,
+			ExpectedFound:   true,
+		},
+		"notification receipt is preferred": {
+			Observation: Observation{
+				ObservedAt:             publishDate.Add(7 * time.Second),
+				NotificationReceivedAt: publishDate.Add(2500 * time.Millisecond),
+			},
+			ExpectedLatency: 2500 * time.Millisecond,
+			ExpectedFound:   true,
+		},
+		"seen before the publish date": {
+			Observation:   Observation{ObservedAt: publishDate.Add(-time.Second)},
+			ExpectedFound: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pm := PublishMetric{PublishDate: publishDate, Observation: test.Observation}
+
+			latency, found := pm.Latency()
+			assert.Equal(t, test.ExpectedFound, found)
+			assert.Equal(t, test.ExpectedLatency, latency)
+		})
+	}
+}
+
+func TestPublishMetricObservationIsSerialised(t *testing.T) {
+	publishDate := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	pm := PublishMetric{
+		UUID:        "077f5ac2-0491-420e-a5d0-982e0f86204b",
+		PublishDate: publishDate,
+		Observation: Observation{
+			ObservedAt:               publishDate.Add(7 * time.Second),
+			NotificationLastModified: publishDate.Add(time.Second),
+			NotificationReceivedAt:   publishDate.Add(2 * time.Second),
+		},
+	}
+
+	data, err := json.Marshal(pm)
+	require.NoError(t, err)
+
+	var actual PublishMetric
+	require.NoError(t, json.Unmarshal(data, &actual))
+	assert.True(t, pm.ObservedAt.Equal(actual.ObservedAt))
+	assert.True(t, pm.NotificationLastModified.Equal(actual.NotificationLastModified))
+	assert.True(t, pm.NotificationReceivedAt.Equal(actual.NotificationReceivedAt))
+
+	data, err = json.Marshal(PublishMetric{UUID: pm.UUID})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "observedAt", "unknown times should be omitted")
+}
+
+func TestPublishMetricDiagnosis(t *testing.T) {
+	tests := map[string]struct {
+		Attempts          []CheckAttempt
+		ExpectedDiagnosis string
+	}{
+		"no attempts": {},
+		"error": {
+			Attempts:          []CheckAttempt{{StatusCode: 404}, {Error: "connection refused"}},
+			ExpectedDiagnosis: "the last attempt failed: connection refused",
+		},
+		"previous publish": {
+			Attempts:          []CheckAttempt{{StatusCode: 200, PublishReference: "tid_previous"}, {StatusCode: 200, PublishReference: "tid_previous"}},
+			ExpectedDiagnosis: "the endpoint kept returning the previous publishReference [tid_previous]",
+		},
+		"other publish": {
+			Attempts:          []CheckAttempt{{StatusCode: 404}, {StatusCode: 200, PublishReference: "tid_other"}},
+			ExpectedDiagnosis: "the endpoint last returned the publishReference [tid_other]",
+		},
+		"not found": {
+			Attempts:          []CheckAttempt{{StatusCode: 404}, {StatusCode: 404}},
+			ExpectedDiagnosis: "the endpoint kept responding with status code 404",
+		},
+		"status code changed": {
+			Attempts:          []CheckAttempt{{StatusCode: 503}, {StatusCode: 404}},
+			ExpectedDiagnosis: "the endpoint last responded with status code 404",
+		},
+		"notification not found": {
+			Attempts:          []CheckAttempt{{}, {}},
+			ExpectedDiagnosis: "the publish was never found at the endpoint",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pm := PublishMetric{TID: "tid_current", Attempts: test.Attempts}
+			assert.Equal(t, test.ExpectedDiagnosis, pm.Diagnosis())
+		})
+	}
+}
+
+func TestPublishMetricAttemptsAreSerialised(t *testing.T) {
+	at := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	pm := PublishMetric{
+		UUID:     "077f5ac2-0491-420e-a5d0-982e0f86204b",
+		TID:      "tid_current",
+		Outcome:  OutcomeFailure,
+		Attempts: []CheckAttempt{{At: at, URL: "http://localhost/content/077f5ac2-0491-420e-a5d0-982e0f86204b", StatusCode: 404, Latency: 20 * time.Millisecond}},
+	}
+
+	data, err := json.Marshal(pm)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"diagnosis":"the endpoint kept responding with status code 404"`)
+
+	var actual PublishMetric
+	require.NoError(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, pm.Attempts, actual.Attempts)
+}
+
+func TestPublishMetricInconclusiveReasonIsSerialised(t *testing.T) {
+	pm := PublishMetric{UUID: "077f5ac2-0491-420e-a5d0-982e0f86204b", Outcome: OutcomeInconclusive, Reason: ReasonAuth}
+
+	data, err := json.Marshal(pm)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"inconclusiveReason":"auth"`)
+
+	var actual PublishMetric
+	require.NoError(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, OutcomeInconclusive, actual.Outcome)
+	assert.Equal(t, ReasonAuth, actual.Reason)
+}
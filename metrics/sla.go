@@ -0,0 +1,309 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+)
+
+// slaBucketsPerWindow is the number of counters every window is divided in.
+// Publishes leave a window one bucket (1/60th of the window) at a time.
+const slaBucketsPerWindow = 60
+
+// DefaultSLAWindows are the windows SLA compliance is computed over if none is configured.
+var DefaultSLAWindows = []string{"1h", "24h", "7d", "30d"}
+
+// The dimensions SLA compliance can be grouped by.
+const (
+	SLAByContentType   = "contentType"
+	SLAByEndpoint      = "endpoint"
+	SLAByEnvironment   = "environment"
+	SLAByEditorialDesk = "editorialDesk"
+)
+
+// SLADimensions are all the dimensions SLA compliance can be grouped by, in reporting order.
+var SLADimensions = []string{SLAByContentType, SLAByEndpoint, SLAByEnvironment, SLAByEditorialDesk}
+
+// SLAKey identifies the publishes SLA compliance is computed for.
+type SLAKey struct {
+	ContentType   string `json:"contentType,omitempty"`
+	Endpoint      string `json:"endpoint,omitempty"`
+	Environment   string `json:"environment,omitempty"`
+	EditorialDesk string `json:"editorialDesk,omitempty"`
+}
+
+// Dimension returns the value of the named dimension of k.
+func (k SLAKey) Dimension(name string) string {
+	switch name {
+	case SLAByContentType:
+		return k.ContentType
+	case SLAByEndpoint:
+		return k.Endpoint
+	case SLAByEnvironment:
+		return k.Environment
+	case SLAByEditorialDesk:
+		return k.EditorialDesk
+	}
+	return ""
+}
+
+// groupBy keeps only the given dimensions of k.
+func (k SLAKey) groupBy(dimensions []string) SLAKey {
+	var grouped SLAKey
+	for _, d := range dimensions {
+		switch d {
+		case SLAByContentType:
+			grouped.ContentType = k.ContentType
+		case SLAByEndpoint:
+			grouped.Endpoint = k.Endpoint
+		case SLAByEnvironment:
+			grouped.Environment = k.Environment
+		case SLAByEditorialDesk:
+			grouped.EditorialDesk = k.EditorialDesk
+		}
+	}
+	return grouped
+}
+
+// slaBucket counts the publishes of a slice of a window.
+type slaBucket struct {
+	index        int64 // the bucket number since the epoch, identifies stale buckets in the ring
+	total        int
+	succeeded    int
+	inconclusive int // not part of the total
+}
+
+// slaWindow is a ring of buckets covering the last length of time.
+type slaWindow struct {
+	name       string
+	length     time.Duration
+	bucketSize time.Duration
+}
+
+func (w slaWindow) bucketIndex(t time.Time) int64 {
+	return t.UnixNano() / int64(w.bucketSize)
+}
+
+// SLARow is the SLA compliance of a group of publishes.
+type SLARow struct {
+	SLAKey
+	Total      int     `json:"total"`
+	Succeeded  int     `json:"succeeded"`
+	Failed     int     `json:"failed"`
+	Compliance float64 `json:"compliance"` // percentage of the publishes that met the SLA
+	// publishes the monitor could not check, they are not part of the total nor the compliance
+	Inconclusive int `json:"inconclusive"`
+}
+
+// SLAReport is the SLA compliance over a window, grouped by some dimensions.
+type SLAReport struct {
+	Window  string    `json:"window"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	GroupBy []string  `json:"groupBy"`
+	Rows    []SLARow  `json:"rows"`
+}
+
+// SLA implements Destination interface to compute the SLA compliance of the regular publishes
+// per content type, endpoint alias, environment and editorial desk over rolling windows.
+// Publishes are counted in the windows by publish date.
+type SLA struct {
+	mu       sync.Mutex
+	windows  []slaWindow
+	counters map[SLAKey][][]slaBucket // a ring of buckets per window
+	now      func() time.Time
+}
+
+// NewSLA returns an SLA computing compliance over the windows in cfg,
+// or an error if a window cannot be parsed.
+func NewSLA(cfg config.SLAConfig) (*SLA, error) {
+	names := cfg.Windows
+	if len(names) == 0 {
+		names = DefaultSLAWindows
+	}
+
+	sla := &SLA{
+		counters: make(map[SLAKey][][]slaBucket),
+		now:      time.Now,
+	}
+	for _, name := range names {
+		length, err := ParseSLAWindow(name)
+		if err != nil {
+			return nil, err
+		}
+		sla.windows = append(sla.windows, slaWindow{
+			name:       name,
+			length:     length,
+			bucketSize: length / slaBucketsPerWindow,
+		})
+	}
+	return sla, nil
+}
+
+// ParseSLAWindow parses a duration which, on top of the time.ParseDuration units, can be in days, ex. 7d.
+func ParseSLAWindow(window string) (time.Duration, error) {
+	var length time.Duration
+	var err error
+	if days, found := strings.CutSuffix(window, "d"); found {
+		var n int
+		n, err = strconv.Atoi(days)
+		length = time.Duration(n) * 24 * time.Hour
+	} else {
+		length, err = time.ParseDuration(window)
+	}
+
+	if err != nil || length < slaBucketsPerWindow*time.Second {
+		return 0, fmt.Errorf("invalid SLA window [%s], it should be at least 1m", window)
+	}
+	return length, nil
+}
+
+// Windows returns the names of the windows SLA compliance is computed over.
+func (s *SLA) Windows() []string {
+	names := make([]string, 0, len(s.windows))
+	for _, w := range s.windows {
+		names = append(names, w.name)
+	}
+	return names
+}
+
+// Send counts pm in every window. Inconclusive metrics are counted apart,
+// capability metrics and the other ones which are not SLA outcomes are skipped.
+func (s *SLA) Send(pm PublishMetric) {
+	outcome := pm.GetOutcome()
+	if pm.Capability != nil || !outcome.IsSLAOutcome() && outcome != OutcomeInconclusive {
+		return
+	}
+
+	key := SLAKey{
+		ContentType:   pm.ContentType,
+		Endpoint:      pm.Config.Alias,
+		Environment:   pm.Platform,
+		EditorialDesk: pm.EditorialDesk,
+	}
+	at := pm.PublishDate
+	if at.IsZero() {
+		at = s.now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rings, found := s.counters[key]
+	if !found {
+		rings = make([][]slaBucket, len(s.windows))
+		for i := range rings {
+			rings[i] = make([]slaBucket, slaBucketsPerWindow)
+		}
+		s.counters[key] = rings
+	}
+
+	now := s.now()
+	for i, w := range s.windows {
+		index := w.bucketIndex(at)
+		if index <= w.bucketIndex(now)-slaBucketsPerWindow {
+			continue // already out of the window
+		}
+
+		b := &rings[i][index%slaBucketsPerWindow]
+		if b.index != index {
+			*b = slaBucket{index: index}
+		}
+		switch {
+		case outcome == OutcomeInconclusive:
+			b.inconclusive++
+		case pm.PublishOK:
+			b.total++
+			b.succeeded++
+		default:
+			b.total++
+		}
+	}
+}
+
+// Report returns the SLA compliance over window, grouped by the given dimensions.
+// Rows are sorted by dimensions, in the order of SLADimensions.
+func (s *SLA) Report(window string, groupBy []string) (SLAReport, error) {
+	wi := -1
+	for i, w := range s.windows {
+		if w.name == window {
+			wi = i
+		}
+	}
+	if wi < 0 {
+		return SLAReport{}, fmt.Errorf("unknown SLA window [%s], it should be one of %v", window, s.Windows())
+	}
+	if err := validateSLADimensions(groupBy); err != nil {
+		return SLAReport{}, err
+	}
+
+	w := s.windows[wi]
+	now := s.now()
+	current := w.bucketIndex(now)
+	rows := make(map[SLAKey]*SLARow)
+
+	s.mu.Lock()
+	for key, rings := range s.counters {
+		for _, b := range rings[wi] {
+			if b.total == 0 && b.inconclusive == 0 || b.index <= current-slaBucketsPerWindow {
+				continue
+			}
+
+			grouped := key.groupBy(groupBy)
+			row, found := rows[grouped]
+			if !found {
+				row = &SLARow{SLAKey: grouped}
+				rows[grouped] = row
+			}
+			row.Total += b.total
+			row.Succeeded += b.succeeded
+			row.Inconclusive += b.inconclusive
+		}
+	}
+	s.mu.Unlock()
+
+	report := SLAReport{
+		Window:  w.name,
+		From:    time.Unix(0, (current-slaBucketsPerWindow+1)*int64(w.bucketSize)).UTC(),
+		To:      now.UTC(),
+		GroupBy: groupBy,
+		Rows:    make([]SLARow, 0, len(rows)),
+	}
+	for _, row := range rows {
+		row.Failed = row.Total - row.Succeeded
+		row.Compliance = 100 // none of the publishes is known to have missed the SLA
+		if row.Total > 0 {
+			row.Compliance = math.Round(float64(row.Succeeded)/float64(row.Total)*10000) / 100
+		}
+		report.Rows = append(report.Rows, *row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		for _, d := range SLADimensions {
+			a, b := report.Rows[i].Dimension(d), report.Rows[j].Dimension(d)
+			if a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+	return report, nil
+}
+
+func validateSLADimensions(dimensions []string) error {
+	for _, d := range dimensions {
+		valid := false
+		for _, known := range SLADimensions {
+			valid = valid || d == known
+		}
+		if !valid {
+			return fmt.Errorf("unknown SLA dimension [%s], it should be one of %v", d, SLADimensions)
+		}
+	}
+	return nil
+}
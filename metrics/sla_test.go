@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func slaMetric(contentType, alias, environment string, publishOK bool, publishDate time.Time) PublishMetric {
+	outcome := OutcomeFailure
+	if publishOK {
+		outcome = OutcomeSuccess
+	}
+	return PublishMetric{
+		ContentType:   contentType,
+		EditorialDesk: "/FT/WorldNews",
+		PublishOK:     publishOK,
+		Outcome:       outcome,
+		PublishDate:   publishDate,
+		Platform:      environment,
+		Config:        config.MetricConfig{Alias: alias},
+	}
+}
+
+func TestParseSLAWindow(t *testing.T) {
+	tests := map[string]struct {
+		Window         string
+		ExpectedLength time.Duration
+		ExpectedError  bool
+	}{
+		"hours":     {Window: "24h", ExpectedLength: 24 * time.Hour},
+		"days":      {Window: "30d", ExpectedLength: 30 * 24 * time.Hour},
+		"too short": {Window: "30s", ExpectedError: true},
+		"invalid":   {Window: "a week", ExpectedError: true},
+		"bad days":  {Window: "xd", ExpectedError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			length, err := ParseSLAWindow(test.Window)
+			if test.ExpectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedLength, length)
+		})
+	}
+}
+
+func TestNewSLAInvalidWindow(t *testing.T) {
+	_, err := NewSLA(config.SLAConfig{Windows: []string{"1h", "monthly"}})
+	assert.Error(t, err)
+}
+
+func TestSLAReport(t *testing.T) {
+	sla, err := NewSLA(config.SLAConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSLAWindows, sla.Windows())
+
+	now := time.Date(2023, 10, 31, 12, 0, 0, 0, time.UTC)
+	sla.now = func() time.Time { return now }
+
+	article := "application/vnd.ft-upp-article+json"
+	sla.Send(slaMetric(article, "content", "eu", true, now.Add(-10*time.Minute)))
+	sla.Send(slaMetric(article, "content", "eu", false, now.Add(-20*time.Minute)))
+	sla.Send(slaMetric(article, "content", "us", true, now.Add(-2*time.Hour)))
+	sla.Send(slaMetric(article, "lists", "eu", true, now.Add(-3*24*time.Hour)))
+	sla.Send(slaMetric(article, "lists", "eu", true, now.Add(-40*24*time.Hour)))
+
+	ignored := slaMetric(article, "content", "eu", false, now)
+	ignored.Outcome = OutcomeIgnored
+	sla.Send(ignored)
+	late := slaMetric(article, "content", "eu", true, now)
+	late.Outcome = OutcomeLate
+	sla.Send(late)
+	inconclusive := slaMetric(article, "content", "eu", false, now)
+	inconclusive.Outcome = OutcomeInconclusive
+	sla.Send(inconclusive)
+	capability := slaMetric(article, "content", "eu", false, now)
+	capability.Capability = &config.Capability{Name: "article-publish"}
+	sla.Send(capability)
+
+	report, err := sla.Report("1h", SLADimensions)
+	require.NoError(t, err)
+	assert.Equal(t, "1h", report.Window)
+	assert.Equal(t, now, report.To)
+	assert.Equal(t, []SLARow{{
+		SLAKey:       SLAKey{ContentType: article, Endpoint: "content", Environment: "eu", EditorialDesk: "/FT/WorldNews"},
+		Total:        2,
+		Succeeded:    1,
+		Failed:       1,
+		Compliance:   50,
+		Inconclusive: 1,
+	}}, report.Rows)
+
+	report, err = sla.Report("24h", []string{SLAByEndpoint})
+	require.NoError(t, err)
+	assert.Equal(t, []SLARow{{SLAKey: SLAKey{Endpoint: "content"}, Total: 3, Succeeded: 2, Failed: 1, Compliance: 66.67, Inconclusive: 1}}, report.Rows)
+
+	report, err = sla.Report("30d", []string{SLAByEnvironment, SLAByEndpoint})
+	require.NoError(t, err)
+	assert.Equal(t, []SLARow{
+		{SLAKey: SLAKey{Endpoint: "content", Environment: "eu"}, Total: 2, Succeeded: 1, Failed: 1, Compliance: 50, Inconclusive: 1},
+		{SLAKey: SLAKey{Endpoint: "content", Environment: "us"}, Total: 1, Succeeded: 1, Compliance: 100},
+		{SLAKey: SLAKey{Endpoint: "lists", Environment: "eu"}, Total: 1, Succeeded: 1, Compliance: 100},
+	}, report.Rows)
+
+	report, err = sla.Report("7d", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []SLARow{{Total: 4, Succeeded: 3, Failed: 1, Compliance: 75, Inconclusive: 1}}, report.Rows)
+}
+
+func TestSLAReportOfInconclusivePublishesOnly(t *testing.T) {
+	sla, err := NewSLA(config.SLAConfig{Windows: []string{"1h"}})
+	require.NoError(t, err)
+
+	inconclusive := slaMetric("application/vnd.ft-upp-article+json", "content", "eu", false, time.Now())
+	inconclusive.Outcome = OutcomeInconclusive
+	sla.Send(inconclusive)
+
+	report, err := sla.Report("1h", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []SLARow{{Compliance: 100, Inconclusive: 1}}, report.Rows)
+}
+
+func TestSLAReportRollsOver(t *testing.T) {
+	sla, err := NewSLA(config.SLAConfig{Windows: []string{"1h"}})
+	require.NoError(t, err)
+
+	start := time.Date(2023, 10, 31, 12, 0, 0, 0, time.UTC)
+	now := start
+	sla.now = func() time.Time { return now }
+	sla.Send(slaMetric("", "content", "eu", false, start))
+
+	now = start.Add(30 * time.Minute)
+	sla.Send(slaMetric("", "content", "eu", true, now))
+
+	report, err := sla.Report("1h", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []SLARow{{Total: 2, Succeeded: 1, Failed: 1, Compliance: 50}}, report.Rows)
+
+	now = start.Add(61 * time.Minute)
+	report, err = sla.Report("1h", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []SLARow{{Total: 1, Succeeded: 1, Compliance: 100}}, report.Rows)
+
+	// the bucket of the first publish is reused
+	sla.Send(slaMetric("", "content", "eu", true, now))
+	report, err = sla.Report("1h", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []SLARow{{Total: 2, Succeeded: 2, Compliance: 100}}, report.Rows)
+}
+
+func TestSLAReportInvalidQuery(t *testing.T) {
+	sla, err := NewSLA(config.SLAConfig{})
+	require.NoError(t, err)
+
+	_, err = sla.Report("2h", nil)
+	assert.Error(t, err)
+
+	_, err = sla.Report("1h", []string{"publication"})
+	assert.Error(t, err)
+}
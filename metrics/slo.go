@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+)
+
+const defaultSLOWindow = "30d"
+
+// BurnRateAlert fires when the error budget is burnt faster than Threshold times the sustainable rate
+// over both the Long and the Short windows. The short window makes the alert stop soon after the problem is gone.
+type BurnRateAlert struct {
+	Long      string  `json:"long"`
+	Short     string  `json:"short"`
+	Threshold float64 `json:"threshold"`
+	Severity  uint8   `json:"severity"`
+}
+
+// BurnRateAlerts are the multi-window burn-rate alerts evaluated for every SLO, most severe first.
+// For a 30 days SLO they fire when 2% of the budget is burnt in an hour, 5% in 6 hours,
+// 10% in a day or 10% in 3 days.
+var BurnRateAlerts = []BurnRateAlert{
+	{Long: "1h", Short: "5m", Threshold: 14.4, Severity: 1},
+	{Long: "6h", Short: "30m", Threshold: 6, Severity: 1},
+	{Long: "24h", Short: "2h", Threshold: 3, Severity: 2},
+	{Long: "3d", Short: "6h", Threshold: 1, Severity: 3},
+}
+
+// SLOStatus is the state of the error budget of an SLO.
+type SLOStatus struct {
+	Name            string             `json:"name"`
+	Objective       float64            `json:"objective"`
+	Window          string             `json:"window"`
+	Total           int                `json:"total"`
+	Failed          int                `json:"failed"`
+	BudgetRemaining float64            `json:"budgetRemaining"` // percentage of the error budget of the window left, negative once exhausted
+	BurnRates       map[string]float64 `json:"burnRates"`       // window to burn rate, 1 burns exactly the budget over the SLO window
+	Alert           *BurnRateAlert     `json:"alert,omitempty"` // the most severe alert firing, if any
+}
+
+// SLOs implements Destination interface to evaluate the error budgets of the configured SLOs
+// against the regular publishes, using an SLA over the SLO and burn-rate alert windows.
+type SLOs struct {
+	slos []config.SLOConfig
+	sla  *SLA
+}
+
+// NewSLOs returns SLOs evaluating cfgs, or an error if an SLO is invalid.
+func NewSLOs(cfgs []config.SLOConfig) (*SLOs, error) {
+	var windows []string
+	seen := make(map[string]bool)
+	addWindow := func(window string) {
+		if !seen[window] {
+			seen[window] = true
+			windows = append(windows, window)
+		}
+	}
+	for _, alert := range BurnRateAlerts {
+		addWindow(alert.Long)
+		addWindow(alert.Short)
+	}
+
+	slos := make([]config.SLOConfig, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("SLO name is missing")
+		}
+		if cfg.Objective <= 0 || cfg.Objective >= 100 {
+			return nil, fmt.Errorf("invalid objective %v for SLO [%s], it should be a percentage between 0 and 100 excluded", cfg.Objective, cfg.Name)
+		}
+		if cfg.Window == "" {
+			cfg.Window = defaultSLOWindow
+		}
+		addWindow(cfg.Window)
+		slos = append(slos, cfg)
+	}
+
+	sla, err := NewSLA(config.SLAConfig{Windows: windows})
+	if err != nil {
+		return nil, err
+	}
+	return &SLOs{slos: slos, sla: sla}, nil
+}
+
+// Send counts pm in the SLO windows.
+func (s *SLOs) Send(pm PublishMetric) {
+	s.sla.Send(pm)
+}
+
+// Statuses evaluates every SLO, in configuration order.
+func (s *SLOs) Statuses() []SLOStatus {
+	statuses := make([]SLOStatus, 0, len(s.slos))
+	for _, slo := range s.slos {
+		statuses = append(statuses, s.evaluate(slo))
+	}
+	return statuses
+}
+
+func (s *SLOs) evaluate(slo config.SLOConfig) SLOStatus {
+	budget := 1 - slo.Objective/100
+	status := SLOStatus{
+		Name:      slo.Name,
+		Objective: slo.Objective,
+		Window:    slo.Window,
+		BurnRates: make(map[string]float64),
+	}
+
+	burnRate := func(window string) float64 {
+		total, failed := s.count(slo, window)
+		if total == 0 {
+			return 0
+		}
+		return math.Round(float64(failed)/float64(total)/budget*100) / 100
+	}
+
+	status.Total, status.Failed = s.count(slo, slo.Window)
+	status.BudgetRemaining = 100
+	if status.Total > 0 {
+		spent := float64(status.Failed) / float64(status.Total) / budget
+		status.BudgetRemaining = math.Round((1-spent)*10000) / 100
+	}
+
+	for _, alert := range BurnRateAlerts {
+		long, short := burnRate(alert.Long), burnRate(alert.Short)
+		status.BurnRates[alert.Long] = long
+		status.BurnRates[alert.Short] = short
+		if status.Alert == nil && long > alert.Threshold && short > alert.Threshold {
+			firing := alert
+			status.Alert = &firing
+		}
+	}
+	return status
+}
+
+// count returns how many publishes matching slo were checked within window and how many failed.
+func (s *SLOs) count(slo config.SLOConfig, window string) (total int, failed int) {
+	report, err := s.sla.Report(window, SLADimensions)
+	if err != nil {
+		return 0, 0 // every window was validated by NewSLOs
+	}
+
+	for _, row := range report.Rows {
+		if matchesSLO(slo, row.SLAKey) {
+			total += row.Total
+			failed += row.Failed
+		}
+	}
+	return total, failed
+}
+
+func matchesSLO(slo config.SLOConfig, key SLAKey) bool {
+	switch {
+	case slo.ContentType != "" && key.ContentType != slo.ContentType:
+		return false
+	case slo.Endpoint != "" && key.Endpoint != slo.Endpoint:
+		return false
+	case slo.Environment != "" && key.Environment != slo.Environment:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSLOsInvalidConfig(t *testing.T) {
+	tests := map[string]config.SLOConfig{
+		"missing name":      {Objective: 99},
+		"missing objective": {Name: "publishes"},
+		"100% objective":    {Name: "publishes", Objective: 100},
+		"invalid window":    {Name: "publishes", Objective: 99, Window: "a month"},
+	}
+
+	for name, slo := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSLOs([]config.SLOConfig{slo})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSLOsStatuses(t *testing.T) {
+	now := time.Date(2023, 10, 31, 12, 0, 0, 0, time.UTC)
+	send := func(slos *SLOs, n int, publishOK bool, environment string, ago time.Duration) {
+		for i := 0; i < n; i++ {
+			slos.Send(slaMetric("application/vnd.ft-upp-article+json", "content", environment, publishOK, now.Add(-ago)))
+		}
+	}
+
+	tests := map[string]struct {
+		Publishes               func(slos *SLOs)
+		ExpectedTotal           int
+		ExpectedFailed          int
+		ExpectedBudgetRemaining float64
+		ExpectedSeverity        uint8 // 0 if no alert should fire
+	}{
+		"no publishes": {
+			Publishes:               func(slos *SLOs) {},
+			ExpectedBudgetRemaining: 100,
+		},
+		"within budget": {
+			Publishes: func(slos *SLOs) {
+				send(slos, 999, true, "eu", 10*time.Minute)
+				send(slos, 1, false, "eu", 10*time.Minute)
+			},
+			ExpectedTotal:           1000,
+			ExpectedFailed:          1,
+			ExpectedBudgetRemaining: 90,
+		},
+		"fast burn": {
+			Publishes: func(slos *SLOs) {
+				send(slos, 80, true, "eu", 30*time.Minute)
+				send(slos, 20, false, "eu", 2*time.Minute)
+			},
+			ExpectedTotal:           100,
+			ExpectedFailed:          20,
+			ExpectedBudgetRemaining: -1900,
+			ExpectedSeverity:        1,
+		},
+		"slow burn": {
+			Publishes: func(slos *SLOs) {
+				send(slos, 98, true, "eu", 5*time.Hour)
+				send(slos, 2, false, "eu", 5*time.Hour)
+				send(slos, 100, true, "eu", 20*24*time.Hour)
+			},
+			ExpectedTotal:           200,
+			ExpectedFailed:          2,
+			ExpectedBudgetRemaining: 0,
+			ExpectedSeverity:        3,
+		},
+		"failures in other environments are not counted": {
+			Publishes: func(slos *SLOs) {
+				send(slos, 10, true, "eu", 2*time.Minute)
+				send(slos, 10, false, "us", 2*time.Minute)
+			},
+			ExpectedTotal:           10,
+			ExpectedBudgetRemaining: 100,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			slos, err := NewSLOs([]config.SLOConfig{{Name: "eu-publishes", Objective: 99, Environment: "eu"}})
+			require.NoError(t, err)
+			slos.sla.now = func() time.Time { return now }
+
+			test.Publishes(slos)
+
+			statuses := slos.Statuses()
+			require.Len(t, statuses, 1)
+			status := statuses[0]
+			assert.Equal(t, "30d", status.Window)
+			assert.Equal(t, test.ExpectedTotal, status.Total)
+			assert.Equal(t, test.ExpectedFailed, status.Failed)
+			assert.Equal(t, test.ExpectedBudgetRemaining, status.BudgetRemaining)
+			if test.ExpectedSeverity == 0 {
+				assert.Nil(t, status.Alert)
+				return
+			}
+			require.NotNil(t, status.Alert)
+			assert.Equal(t, test.ExpectedSeverity, status.Alert.Severity)
+		})
+	}
+}
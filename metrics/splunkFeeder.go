@@ -1,25 +1,158 @@
 package metrics
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"os"
+	"time"
 )
 
+// SplunkEventSchemaVersion is the version of the structured Splunk events.
+// It must be increased whenever a field of SplunkEvent is renamed, removed or changes meaning.
+const SplunkEventSchemaVersion = 1
+
+// SplunkEvent is the structured form of a PublishMetric sent to Splunk.
+// The fields of the key=value lines keep the same names.
+type SplunkEvent struct {
+	SchemaVersion      int      `json:"schemaVersion"`
+	UUID               string   `json:"UUID"`
+	ReadEnv            string   `json:"readEnv"`
+	TID                string   `json:"transaction_id"`
+	PublishDate        int64    `json:"publishDate"` // unix nanoseconds
+	PublishOK          bool     `json:"publishOk"`
+	Outcome            Outcome  `json:"outcome"`
+	Duration           int      `json:"duration"` // upper bound of the publish interval
+	IntervalLowerBound int      `json:"intervalLowerBound"`
+	Endpoint           string   `json:"endpoint"`
+	EndpointURL        string   `json:"endpointURL"`
+	ContentType        string   `json:"contentType"`
+	EditorialDesk      string   `json:"editorialDesk"`
+	Publication        []string `json:"publication"`
+	IsMarkedDeleted    bool     `json:"isMarkedDeleted"`
+	Capability         string   `json:"capability,omitempty"`
+
+	// why the endpoint could not be checked, present only for the inconclusive outcome
+	Reason InconclusiveReason `json:"inconclusiveReason,omitempty"`
+
+	// precise times the content was seen, present only if recorded
+	Latency                  *float64 `json:"latency,omitempty"`                  // seconds between the publish date and when the content was seen
+	Lateness                 *float64 `json:"lateness,omitempty"`                 // seconds between the SLA and when late content was seen
+	ObservedAt               int64    `json:"observedAt,omitempty"`               // unix nanoseconds
+	NotificationLastModified int64    `json:"notificationLastModified,omitempty"` // unix nanoseconds
+	NotificationReceivedAt   int64    `json:"notificationReceivedAt,omitempty"`   // unix nanoseconds
+}
+
+func unixNanoOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// NewSplunkEvent returns the structured Splunk event for pm.
+func NewSplunkEvent(pm PublishMetric) SplunkEvent {
+	event := SplunkEvent{
+		SchemaVersion:      SplunkEventSchemaVersion,
+		UUID:               pm.UUID,
+		ReadEnv:            pm.Platform,
+		TID:                pm.TID,
+		PublishDate:        pm.PublishDate.UnixNano(),
+		PublishOK:          pm.PublishOK,
+		Outcome:            pm.GetOutcome(),
+		Reason:             pm.Reason,
+		Duration:           pm.PublishInterval.UpperBound,
+		IntervalLowerBound: pm.PublishInterval.LowerBound,
+		Endpoint:           pm.Config.Alias,
+		EndpointURL:        pm.Endpoint.String(),
+		ContentType:        pm.ContentType,
+		EditorialDesk:      pm.EditorialDesk,
+		Publication:        pm.Publication,
+		IsMarkedDeleted:    pm.IsMarkedDeleted,
+
+		ObservedAt:               unixNanoOrZero(pm.ObservedAt),
+		NotificationLastModified: unixNanoOrZero(pm.NotificationLastModified),
+		NotificationReceivedAt:   unixNanoOrZero(pm.NotificationReceivedAt),
+	}
+	if latency, found := pm.Latency(); found {
+		seconds := latency.Seconds()
+		event.Latency = &seconds
+	}
+	if event.Outcome == OutcomeLate {
+		seconds := pm.Lateness.Seconds()
+		event.Lateness = &seconds
+	}
+	if event.Publication == nil {
+		event.Publication = []string{}
+	}
+	if pm.Capability != nil {
+		event.Capability = pm.Capability.Name
+	}
+	return event
+}
+
 // SplunkFeeder implements Destination interface to send PublishMetrics to Splunk.
 // This is achieved by writing the metric into a file which is indexed by Splunk.
 type SplunkFeeder struct {
-	MetricLog *log.Logger
+	MetricLog  *log.Logger
+	jsonFormat bool
 }
 
 // NewSplunkFeeder returns a SplunkFeeder which will write the PublishMetrics to the file at filePath.
 // If the file exists, it will be appended to.
 func NewSplunkFeeder(logPrefix string) *SplunkFeeder {
 	logger := log.New(os.Stdout, logPrefix, log.Ldate|log.Ltime|log.Lmicroseconds|log.LUTC)
-	return &SplunkFeeder{logger}
+	return &SplunkFeeder{MetricLog: logger}
+}
+
+// NewJSONSplunkFeeder returns a SplunkFeeder which writes each PublishMetric as a SplunkEvent on its own line.
+func NewJSONSplunkFeeder() *SplunkFeeder {
+	logger := log.New(os.Stdout, "", 0)
+	return &SplunkFeeder{MetricLog: logger, jsonFormat: true}
 }
 
 // Send logs pm into a file.
 func (sf SplunkFeeder) Send(pm PublishMetric) {
-	sf.MetricLog.Printf("UUID=%v readEnv=%v transaction_id=%v publishDate=%v publishOk=%v duration=%v endpoint=%v ",
-		pm.UUID, pm.Platform, pm.TID, pm.PublishDate.UnixNano(), pm.PublishOK, pm.PublishInterval.UpperBound, pm.Config.Alias)
+	if pm.GetOutcome() == OutcomeIgnored {
+		return
+	}
+
+	if sf.jsonFormat {
+		event, err := json.Marshal(NewSplunkEvent(pm))
+		if err != nil {
+			sf.MetricLog.Printf("Cannot serialise metric for UUID=%v transaction_id=%v: %v", pm.UUID, pm.TID, err)
+			return
+		}
+		sf.MetricLog.Print(string(event))
+		return
+	}
+
+	sf.MetricLog.Print(keyValueLine(pm))
+}
+
+// keyValueLine returns the legacy key=value line of pm.
+// Only the success and failure outcomes have a publishOk, the lines of the other ones start with the outcome
+// so that they are neither counted as publishes nor read as failures.
+func keyValueLine(pm PublishMetric) string {
+	outcome := pm.GetOutcome()
+
+	line := ""
+	if !outcome.IsSLAOutcome() {
+		line += fmt.Sprintf("outcome=%v ", outcome)
+	}
+	line += fmt.Sprintf("UUID=%v readEnv=%v transaction_id=%v publishDate=%v ", pm.UUID, pm.Platform, pm.TID, pm.PublishDate.UnixNano())
+	if outcome.IsSLAOutcome() {
+		line += fmt.Sprintf("publishOk=%v ", pm.PublishOK)
+	}
+	line += fmt.Sprintf("duration=%v endpoint=%v ", pm.PublishInterval.UpperBound, pm.Config.Alias)
+	if latency, found := pm.Latency(); found {
+		line += fmt.Sprintf("latency=%.3f ", latency.Seconds())
+	}
+	if outcome == OutcomeLate {
+		line += fmt.Sprintf("lateness=%.3f ", pm.Lateness.Seconds())
+	}
+	if pm.Reason != "" {
+		line += fmt.Sprintf("inconclusiveReason=%v ", pm.Reason)
+	}
+	return line
 }
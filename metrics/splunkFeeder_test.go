@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSplunkMetric() PublishMetric {
+	endpoint, _ := url.Parse("http://localhost/content/")
+	return PublishMetric{
+		UUID:            "077f5ac2-0491-420e-a5d0-982e0f86204b",
+		EditorialDesk:   "/FT/WorldNews",
+		Publication:     []string{"88fdde6c-2aa4-4f78-af02-9f680097cfd6"},
+		ContentType:     "application/vnd.ft-upp-article+json",
+		PublishOK:       true,
+		Outcome:         OutcomeSuccess,
+		PublishDate:     time.Unix(0, 1696161600123000000),
+		Platform:        "eu",
+		PublishInterval: Interval{LowerBound: 5, UpperBound: 10},
+		Config:          config.MetricConfig{Alias: "content"},
+		Endpoint:        *endpoint,
+		TID:             "tid_test",
+		IsMarkedDeleted: true,
+	}
+}
+
+func TestSplunkFeederKeyValueFormat(t *testing.T) {
+	var out bytes.Buffer
+	sf := SplunkFeeder{MetricLog: log.New(&out, "", 0)}
+
+	sf.Send(testSplunkMetric())
+
+	assert.Equal(t, "UUID=077f5ac2-0491-420e-a5d0-982e0f86204b readEnv=eu transaction_id=tid_test publishDate=1696161600123000000 publishOk=true duration=10 endpoint=content \n", out.String())
+}
+
+func TestSplunkFeederJSONFormat(t *testing.T) {
+	var out bytes.Buffer
+	sf := SplunkFeeder{MetricLog: log.New(&out, "", 0), jsonFormat: true}
+
+	sf.Send(testSplunkMetric())
+
+	assert.JSONEq(t, `{
+		"schemaVersion": 1,
+		"UUID": "077f5ac2-0491-420e-a5d0-982e0f86204b",
+		"readEnv": "eu",
+		"transaction_id": "tid_test",
+		"publishDate": 1696161600123000000,
+		"publishOk": true,
+		"outcome": "success",
+		"duration": 10,
+		"intervalLowerBound": 5,
+		"endpoint": "content",
+		"endpointURL": "http://localhost/content/",
+		"contentType": "application/vnd.ft-upp-article+json",
+		"editorialDesk": "/FT/WorldNews",
+		"publication": ["88fdde6c-2aa4-4f78-af02-9f680097cfd6"],
+		"isMarkedDeleted": true
+	}`, out.String())
+}
+
+func TestSplunkFeederReportsLatency(t *testing.T) {
+	pm := testSplunkMetric()
+	pm.ObservedAt = pm.PublishDate.Add(7500 * time.Millisecond)
+	pm.NotificationReceivedAt = pm.PublishDate.Add(6250 * time.Millisecond)
+
+	var out bytes.Buffer
+	sf := SplunkFeeder{MetricLog: log.New(&out, "", 0)}
+	sf.Send(pm)
+	assert.Equal(t, "UUID=077f5ac2-0491-420e-a5d0-982e0f86204b readEnv=eu transaction_id=tid_test publishDate=1696161600123000000 publishOk=true duration=10 endpoint=content latency=6.250 \n", out.String())
+
+	event := NewSplunkEvent(pm)
+	require.NotNil(t, event.Latency)
+	assert.Equal(t, 6.25, *event.Latency)
+	assert.Equal(t, int64(1696161607623000000), event.ObservedAt)
+	assert.Equal(t, int64(1696161606373000000), event.NotificationReceivedAt)
+	assert.Zero(t, event.NotificationLastModified)
+}
+
+func TestSplunkFeederReportsLateness(t *testing.T) {
+	pm := testSplunkMetric()
+	pm.Outcome = OutcomeLate
+	pm.PublishInterval = Interval{LowerBound: 120, UpperBound: 150}
+	pm.Lateness = 25500 * time.Millisecond
+
+	var out bytes.Buffer
+	sf := SplunkFeeder{MetricLog: log.New(&out, "", 0)}
+	sf.Send(pm)
+	assert.Equal(t, "outcome=late UUID=077f5ac2-0491-420e-a5d0-982e0f86204b readEnv=eu transaction_id=tid_test publishDate=1696161600123000000 duration=150 endpoint=content lateness=25.500 \n", out.String())
+
+	event := NewSplunkEvent(pm)
+	require.NotNil(t, event.Lateness)
+	assert.Equal(t, 25.5, *event.Lateness)
+
+	pm.Outcome = OutcomeLost
+	pm.PublishOK = false
+	assert.Nil(t, NewSplunkEvent(pm).Lateness)
+
+	out.Reset()
+	sf.Send(pm)
+	assert.Equal(t, "outcome=lost UUID=077f5ac2-0491-420e-a5d0-982e0f86204b readEnv=eu transaction_id=tid_test publishDate=1696161600123000000 duration=150 endpoint=content \n", out.String())
+	assert.NotContains(t, out.String(), "publishOk", "the failure of the check is already reported")
+}
+
+func TestSplunkFeederReportsInconclusiveReason(t *testing.T) {
+	pm := testSplunkMetric()
+	pm.PublishOK = false
+	pm.Outcome = OutcomeInconclusive
+	pm.Reason = ReasonServerError
+
+	var out bytes.Buffer
+	sf := SplunkFeeder{MetricLog: log.New(&out, "", 0)}
+	sf.Send(pm)
+	assert.Equal(t, "outcome=inconclusive UUID=077f5ac2-0491-420e-a5d0-982e0f86204b readEnv=eu transaction_id=tid_test publishDate=1696161600123000000 duration=10 endpoint=content inconclusiveReason=5xx \n", out.String())
+
+	assert.Equal(t, ReasonServerError, NewSplunkEvent(pm).Reason)
+}
+
+func TestSplunkFeederReportsInterruptedChecks(t *testing.T) {
+	pm := testSplunkMetric()
+	pm.PublishOK = false
+	pm.Outcome = OutcomeInterrupted
+
+	var out bytes.Buffer
+	sf := SplunkFeeder{MetricLog: log.New(&out, "", 0)}
+	sf.Send(pm)
+	assert.Equal(t, "outcome=interrupted UUID=077f5ac2-0491-420e-a5d0-982e0f86204b readEnv=eu transaction_id=tid_test publishDate=1696161600123000000 duration=10 endpoint=content \n", out.String())
+	assert.NotContains(t, out.String(), "publishOk", "an interrupted check should not be read as a failure")
+}
+
+func TestSplunkFeederSkipsIgnoredChecks(t *testing.T) {
+	for name, jsonFormat := range map[string]bool{"kv": false, "json": true} {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			sf := SplunkFeeder{MetricLog: log.New(&out, "", 0), jsonFormat: jsonFormat}
+
+			pm := testSplunkMetric()
+			pm.Outcome = OutcomeIgnored
+			sf.Send(pm)
+
+			assert.Empty(t, out.String())
+		})
+	}
+}
+
+func TestSplunkEventCapability(t *testing.T) {
+	pm := testSplunkMetric()
+	pm.Capability = &config.Capability{Name: "article-publish"}
+
+	b, err := json.Marshal(NewSplunkEvent(pm))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"capability":"article-publish"`)
+}
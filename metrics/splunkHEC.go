@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/giantswarm/retry-go"
+)
+
+const (
+	splunkHECMaxTries   = 3
+	splunkHECRetryDelay = time.Second
+	splunkHECTimeout    = 10 * time.Second
+)
+
+// splunkHECRequest is the envelope of an event sent to the Splunk HTTP Event Collector.
+type splunkHECRequest struct {
+	Time       float64     `json:"time"` // unix seconds
+	Index      string      `json:"index,omitempty"`
+	Source     string      `json:"source,omitempty"`
+	SourceType string      `json:"sourcetype,omitempty"`
+	Event      SplunkEvent `json:"event"`
+}
+
+// SplunkHECSender implements Destination interface to send PublishMetrics
+// as SplunkEvents to the Splunk HTTP Event Collector.
+type SplunkHECSender struct {
+	cfg        config.SplunkHECConfig
+	client     *http.Client
+	retryDelay time.Duration
+	log        *logger.UPPLogger
+}
+
+// NewSplunkHECSender returns a SplunkHECSender posting to the HEC endpoint in cfg.
+func NewSplunkHECSender(cfg config.SplunkHECConfig, log *logger.UPPLogger) *SplunkHECSender {
+	return &SplunkHECSender{
+		cfg:        cfg,
+		client:     &http.Client{Timeout: splunkHECTimeout},
+		retryDelay: splunkHECRetryDelay,
+		log:        log,
+	}
+}
+
+// Send posts pm to the HTTP Event Collector, retrying on errors.
+func (hs *SplunkHECSender) Send(pm PublishMetric) {
+	if pm.GetOutcome() == OutcomeIgnored {
+		return
+	}
+
+	body, err := json.Marshal(splunkHECRequest{
+		Time:       float64(time.Now().UnixNano()) / float64(time.Second),
+		Index:      hs.cfg.Index,
+		Source:     hs.cfg.Source,
+		SourceType: hs.cfg.SourceType,
+		Event:      NewSplunkEvent(pm),
+	})
+	if err != nil {
+		hs.log.WithError(err).WithUUID(pm.UUID).WithTransactionID(pm.TID).Error("Cannot serialise metric for Splunk HEC")
+		return
+	}
+
+	err = retry.Do(
+		func() error { return hs.post(body) },
+		retry.RetryChecker(func(err error) bool { return err != nil }),
+		retry.MaxTries(splunkHECMaxTries),
+		retry.Sleep(hs.retryDelay),
+		retry.Timeout(0),
+	)
+	if err != nil {
+		hs.log.WithError(err).WithUUID(pm.UUID).WithTransactionID(pm.TID).Error("Cannot send metric to Splunk HEC")
+	}
+}
+
+func (hs *SplunkHECSender) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, hs.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Splunk "+hs.cfg.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hs.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("splunk HEC responded with status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplunkHECSenderSend(t *testing.T) {
+	var requests []splunkHECRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Splunk secret-token", r.Header.Get("Authorization"))
+		if len(requests) == 0 {
+			requests = append(requests, splunkHECRequest{})
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var req splunkHECRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+	}))
+	defer server.Close()
+
+	hs := NewSplunkHECSender(config.SplunkHECConfig{
+		URL:        server.URL,
+		Token:      "secret-token",
+		Index:      "content",
+		SourceType: "pam",
+	}, logger.NewUPPLogger("test", "PANIC"))
+	hs.retryDelay = time.Millisecond
+
+	hs.Send(testSplunkMetric())
+
+	require.Len(t, requests, 2, "the event should be resent after an error")
+	req := requests[1]
+	assert.Equal(t, "content", req.Index)
+	assert.Equal(t, "pam", req.SourceType)
+	assert.Equal(t, NewSplunkEvent(testSplunkMetric()), req.Event)
+	assert.InDelta(t, float64(time.Now().Unix()), req.Time, 5)
+}
+
+func TestSplunkHECSenderSkipsIgnoredChecks(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	hs := NewSplunkHECSender(config.SplunkHECConfig{URL: server.URL}, logger.NewUPPLogger("test", "PANIC"))
+	pm := testSplunkMetric()
+	pm.Outcome = OutcomeIgnored
+	hs.Send(pm)
+
+	assert.False(t, called)
+}
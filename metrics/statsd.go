@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+)
+
+// keeps StatsD datagrams below the usual MTU
+const statsDMaxDatagramSize = 1400
+
+// StatsDSender implements GaugeSink interface to send gauges to StatsD over UDP.
+type StatsDSender struct {
+	address string
+	log     *logger.UPPLogger
+}
+
+// NewStatsDSender returns a StatsDSender sending to address.
+func NewStatsDSender(address string, log *logger.UPPLogger) *StatsDSender {
+	return &StatsDSender{address: address, log: log}
+}
+
+// SendGauges sends the gauges in as few datagrams as possible. StatsD timestamps the gauges itself.
+func (ss *StatsDSender) SendGauges(gauges []Gauge, _ time.Time) {
+	if len(gauges) == 0 {
+		return
+	}
+
+	conn, err := net.Dial("udp", ss.address)
+	if err != nil {
+		ss.log.WithError(err).Error("Cannot connect to StatsD")
+		return
+	}
+	defer conn.Close()
+
+	for _, datagram := range encodeStatsDGauges(gauges) {
+		if _, err = conn.Write(datagram); err != nil {
+			ss.log.WithError(err).Error("Cannot send gauges to StatsD")
+			return
+		}
+	}
+}
+
+// encodeStatsDGauges splits the gauges in datagrams, without splitting any line.
+func encodeStatsDGauges(gauges []Gauge) [][]byte {
+	var datagrams [][]byte
+	var buf bytes.Buffer
+	for _, g := range gauges {
+		line := fmt.Sprintf("%s:%s|g\n", g.Path, strconv.FormatFloat(g.Value, 'f', -1, 64))
+		if buf.Len() > 0 && buf.Len()+len(line) > statsDMaxDatagramSize {
+			datagrams = append(datagrams, append([]byte(nil), buf.Bytes()...))
+			buf.Reset()
+		}
+		buf.WriteString(line)
+	}
+	if buf.Len() > 0 {
+		datagrams = append(datagrams, buf.Bytes())
+	}
+	return datagrams
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsDSenderSendGauges(t *testing.T) {
+	srv, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer srv.Close()
+
+	sender := NewStatsDSender(srv.LocalAddr().String(), logger.NewUPPLogger("test", "PANIC"))
+	sender.SendGauges([]Gauge{
+		{Path: "publishes.staging-eu.content.count", Value: 20},
+		{Path: "publishes.staging-eu.content.success_ratio", Value: 0.95},
+	}, time.Now())
+
+	buf := make([]byte, statsDMaxDatagramSize)
+	_ = srv.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := srv.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "publishes.staging-eu.content.count:20|g\npublishes.staging-eu.content.success_ratio:0.95|g\n", string(buf[:n]))
+}
+
+func TestEncodeStatsDGaugesDoesNotSplitLines(t *testing.T) {
+	var gauges []Gauge
+	for i := 0; i < 100; i++ {
+		gauges = append(gauges, Gauge{Path: fmt.Sprintf("publishes.staging-eu.endpoint-%d.success_ratio", i), Value: 1})
+	}
+
+	datagrams := encodeStatsDGauges(gauges)
+
+	require.Greater(t, len(datagrams), 1)
+	lines := 0
+	for _, d := range datagrams {
+		assert.LessOrEqual(t, len(d), statsDMaxDatagramSize)
+		assert.True(t, strings.HasSuffix(string(d), "\n"))
+		lines += strings.Count(string(d), "\n")
+	}
+	assert.Equal(t, 100, lines)
+}
@@ -0,0 +1,263 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/giantswarm/retry-go"
+)
+
+const (
+	defaultWebhookTemplate      = `{"environment":{{json .Environment}},"failures":{{json .Failures}}}`
+	defaultWebhookBatchSize     = 20
+	defaultWebhookBatchInterval = 30 * time.Second
+	defaultWebhookMaxRetries    = 3
+	defaultWebhookDedupWindow   = 10 * time.Minute
+	webhookQueueSize            = 1000
+	webhookRetryDelay           = 2 * time.Second
+	webhookTimeout              = 10 * time.Second
+	webhookCloseTimeout         = 15 * time.Second
+)
+
+// WebhookPayload is the data the webhook template is rendered with.
+type WebhookPayload struct {
+	Environment string
+	Failures    []PublishMetric
+}
+
+// WebhookDestination implements Destination interface to POST publish failures to webhooks.
+// Failures are queued by Send and delivered in batches by Run, until Close is called.
+type WebhookDestination struct {
+	urls          []string
+	template      *template.Template
+	environment   string
+	batchSize     int
+	batchInterval time.Duration
+	maxRetries    int
+	retryDelay    time.Duration
+	dedupWindow   time.Duration
+	client        *http.Client
+	queue         chan PublishMetric
+	mu            sync.Mutex
+	lastQueued    map[string]time.Time // UUID to the time its last failure was queued
+	running       atomic.Bool
+	stop          chan struct{}
+	done          chan struct{}
+	closeOnce     sync.Once
+	log           *logger.UPPLogger
+}
+
+// NewWebhookDestination returns a WebhookDestination for cfg, or an error if its template cannot be parsed.
+func NewWebhookDestination(cfg config.WebhookConfig, environment string, log *logger.UPPLogger) (*WebhookDestination, error) {
+	text := cfg.Template
+	if text == "" {
+		text = defaultWebhookTemplate
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse webhook template: %w", err)
+	}
+
+	wd := &WebhookDestination{
+		urls:          cfg.URLs,
+		template:      tmpl,
+		environment:   environment,
+		batchSize:     cfg.BatchSize,
+		batchInterval: time.Duration(cfg.BatchIntervalSeconds) * time.Second,
+		maxRetries:    cfg.MaxRetries,
+		retryDelay:    webhookRetryDelay,
+		dedupWindow:   time.Duration(cfg.DedupWindowMinutes) * time.Minute,
+		client:        &http.Client{Timeout: webhookTimeout},
+		queue:         make(chan PublishMetric, webhookQueueSize),
+		lastQueued:    make(map[string]time.Time),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		log:           log,
+	}
+	if wd.batchSize <= 0 {
+		wd.batchSize = defaultWebhookBatchSize
+	}
+	if wd.batchInterval <= 0 {
+		wd.batchInterval = defaultWebhookBatchInterval
+	}
+	if wd.maxRetries <= 0 {
+		wd.maxRetries = defaultWebhookMaxRetries
+	}
+	if wd.dedupWindow <= 0 {
+		wd.dedupWindow = defaultWebhookDedupWindow
+	}
+	return wd, nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// Send queues pm to be delivered with the next batch if it is a failure
+// and no failure was queued for the same UUID within the deduplication window.
+func (wd *WebhookDestination) Send(pm PublishMetric) {
+	if pm.GetOutcome() != OutcomeFailure {
+		return
+	}
+	if !wd.markQueued(pm.UUID, time.Now()) {
+		wd.log.WithUUID(pm.UUID).WithTransactionID(pm.TID).Debug("Publish failure already sent to webhooks, skipping")
+		return
+	}
+
+	select {
+	case wd.queue <- pm:
+	default:
+		wd.log.WithUUID(pm.UUID).WithTransactionID(pm.TID).Warn("Webhook queue is full, dropping publish failure")
+	}
+}
+
+func (wd *WebhookDestination) markQueued(uuid string, now time.Time) bool {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+
+	if last, found := wd.lastQueued[uuid]; found && now.Sub(last) < wd.dedupWindow {
+		return false
+	}
+	wd.lastQueued[uuid] = now
+	return true
+}
+
+func (wd *WebhookDestination) pruneQueued(now time.Time) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+
+	for uuid, last := range wd.lastQueued {
+		if now.Sub(last) >= wd.dedupWindow {
+			delete(wd.lastQueued, uuid)
+		}
+	}
+}
+
+// Run delivers the queued failures to the webhooks, once batchSize failures
+// are queued or every batchInterval otherwise, until Close is called.
+func (wd *WebhookDestination) Run() {
+	wd.running.Store(true)
+	defer close(wd.done)
+
+	ticker := time.NewTicker(wd.batchInterval)
+	defer ticker.Stop()
+
+	var batch []PublishMetric
+	for {
+		select {
+		case <-wd.stop:
+			wd.flush(batch)
+			return
+		case pm := <-wd.queue:
+			batch = append(batch, pm)
+			if len(batch) < wd.batchSize {
+				continue
+			}
+		case <-ticker.C:
+			wd.pruneQueued(time.Now())
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		wd.deliver(batch)
+		batch = nil
+	}
+}
+
+// flush delivers batch along with the failures still queued, in batches of batchSize.
+func (wd *WebhookDestination) flush(batch []PublishMetric) {
+	for {
+		select {
+		case pm := <-wd.queue:
+			batch = append(batch, pm)
+			if len(batch) < wd.batchSize {
+				continue
+			}
+		default:
+			if len(batch) > 0 {
+				wd.deliver(batch)
+			}
+			return
+		}
+
+		wd.deliver(batch)
+		batch = nil
+	}
+}
+
+// Close stops Run after the failures still queued are delivered.
+func (wd *WebhookDestination) Close() error {
+	wd.closeOnce.Do(func() {
+		close(wd.stop)
+	})
+
+	if !wd.running.Load() {
+		return nil
+	}
+
+	select {
+	case <-wd.done:
+		return nil
+	case <-time.After(webhookCloseTimeout):
+		return errors.New("timed out delivering the last publish failures to the webhooks")
+	}
+}
+
+func (wd *WebhookDestination) deliver(batch []PublishMetric) {
+	var payload bytes.Buffer
+	err := wd.template.Execute(&payload, WebhookPayload{
+		Environment: wd.environment,
+		Failures:    batch,
+	})
+	if err != nil {
+		wd.log.WithError(err).Error("Cannot render webhook payload")
+		return
+	}
+
+	for _, webhookURL := range wd.urls {
+		err = retry.Do(
+			func() error { return wd.post(webhookURL, payload.Bytes()) },
+			retry.RetryChecker(func(err error) bool { return err != nil }),
+			retry.MaxTries(wd.maxRetries+1),
+			retry.Sleep(wd.retryDelay),
+			retry.Timeout(0),
+		)
+		if err != nil {
+			// webhook URLs often embed credentials, only their host is logged
+			wd.log.WithError(err).Errorf("Cannot send %d publish failures to webhook at [%s]", len(batch), webhookHost(webhookURL))
+		}
+	}
+}
+
+func (wd *WebhookDestination) post(webhookURL string, payload []byte) error {
+	resp, err := wd.client.Post(webhookURL, "application/json", bytes.NewReader(payload)) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("cannot reach webhook at [%s]", webhookHost(webhookURL))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func webhookHost(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "invalid URL"
+	}
+	return u.Host
+}
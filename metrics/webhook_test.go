@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	payloads []string
+	failures int // number of requests to fail before accepting payloads
+	server   *httptest.Server
+}
+
+func newWebhookReceiver(t *testing.T, failures int) *webhookReceiver {
+	wr := &webhookReceiver{failures: failures}
+	wr.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+
+		wr.mu.Lock()
+		defer wr.mu.Unlock()
+		if wr.failures > 0 {
+			wr.failures--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		wr.payloads = append(wr.payloads, string(body))
+	}))
+	t.Cleanup(wr.server.Close)
+	return wr
+}
+
+func (wr *webhookReceiver) received() []string {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	return append([]string(nil), wr.payloads...)
+}
+
+func newTestWebhookDestination(t *testing.T, cfg config.WebhookConfig) *WebhookDestination {
+	wd, err := NewWebhookDestination(cfg, "staging", logger.NewUPPLogger("test", "PANIC"))
+	require.NoError(t, err)
+	wd.batchInterval = 50 * time.Millisecond
+	wd.retryDelay = time.Millisecond
+	go wd.Run()
+	return wd
+}
+
+func failedPublish(uuid, alias string) PublishMetric {
+	return PublishMetric{UUID: uuid, TID: "tid_" + uuid, Platform: "eu", Outcome: OutcomeFailure, Config: config.MetricConfig{Alias: alias}}
+}
+
+func TestWebhookDestinationBatchesFailures(t *testing.T) {
+	receiver := newWebhookReceiver(t, 0)
+	wd := newTestWebhookDestination(t, config.WebhookConfig{URLs: []string{receiver.server.URL}, BatchSize: 2})
+
+	wd.Send(failedPublish("1", "content"))
+	wd.Send(PublishMetric{UUID: "2", PublishOK: true, Outcome: OutcomeSuccess})
+	wd.Send(PublishMetric{UUID: "3", Outcome: OutcomeIgnored})
+	wd.Send(failedPublish("4", "content"))
+	wd.Send(failedPublish("5", "content"))
+
+	require.Eventually(t, func() bool { return len(receiver.received()) == 2 }, time.Second, 10*time.Millisecond)
+
+	var first struct {
+		Environment string          `json:"environment"`
+		Failures    []PublishMetric `json:"failures"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(receiver.received()[0]), &first))
+	assert.Equal(t, "staging", first.Environment)
+	require.Len(t, first.Failures, 2, "a full batch should be sent straight away")
+	assert.Equal(t, "1", first.Failures[0].UUID)
+	assert.Equal(t, "4", first.Failures[1].UUID)
+	assert.Contains(t, receiver.received()[1], `"uuid":"5"`, "the rest should be sent after the batch interval")
+}
+
+func TestWebhookDestinationDeduplicatesUUIDs(t *testing.T) {
+	receiver := newWebhookReceiver(t, 0)
+	wd := newTestWebhookDestination(t, config.WebhookConfig{URLs: []string{receiver.server.URL}})
+
+	wd.Send(failedPublish("1", "content"))
+	wd.Send(failedPublish("1", "notifications"))
+
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.NotContains(t, receiver.received()[0], "notifications")
+
+	wd.markQueued("2", time.Now().Add(-wd.dedupWindow))
+	wd.Send(failedPublish("2", "content"))
+	require.Eventually(t, func() bool { return len(receiver.received()) == 2 }, time.Second, 10*time.Millisecond,
+		"a failure should be sent again once the deduplication window has passed")
+}
+
+func TestWebhookDestinationRetries(t *testing.T) {
+	receiver := newWebhookReceiver(t, 2)
+	other := newWebhookReceiver(t, 0)
+	wd := newTestWebhookDestination(t, config.WebhookConfig{URLs: []string{receiver.server.URL, other.server.URL}, MaxRetries: 2})
+
+	wd.Send(failedPublish("1", "content"))
+
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Len(t, other.received(), 1)
+}
+
+func TestWebhookDestinationTemplate(t *testing.T) {
+	receiver := newWebhookReceiver(t, 0)
+	wd := newTestWebhookDestination(t, config.WebhookConfig{
+		URLs:     []string{receiver.server.URL},
+		Template: `{"text":{{json (printf "%d publish failures in %s" (len .Failures) .Environment)}},"uuids":[{{range $i, $f := .Failures}}{{if $i}},{{end}}{{json $f.UUID}}{{end}}]}`,
+	})
+
+	wd.Send(failedPublish("1", "content"))
+	wd.Send(failedPublish("2", "content"))
+
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.JSONEq(t, `{"text":"2 publish failures in staging","uuids":["1","2"]}`, receiver.received()[0])
+}
+
+func TestWebhookDestinationSendsDiagnosis(t *testing.T) {
+	receiver := newWebhookReceiver(t, 0)
+	wd := newTestWebhookDestination(t, config.WebhookConfig{
+		URLs:     []string{receiver.server.URL},
+		Template: `{"text":{{range .Failures}}{{json (printf "%s: %s" .UUID .Diagnosis)}}{{end}}}`,
+	})
+
+	failure := failedPublish("1", "content")
+	failure.Attempts = []CheckAttempt{{StatusCode: 404}, {StatusCode: 404}}
+	wd.Send(failure)
+
+	require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.JSONEq(t, `{"text":"1: the endpoint kept responding with status code 404"}`, receiver.received()[0])
+}
+
+func TestWebhookDestinationCloseDeliversQueuedFailures(t *testing.T) {
+	receiver := newWebhookReceiver(t, 0)
+	wd := newTestWebhookDestination(t, config.WebhookConfig{URLs: []string{receiver.server.URL}, BatchSize: 2})
+
+	wd.Send(failedPublish("1", "content"))
+	wd.Send(failedPublish("2", "content"))
+	wd.Send(failedPublish("3", "content"))
+	require.Eventually(t, wd.running.Load, time.Second, 10*time.Millisecond)
+	require.NoError(t, wd.Close())
+
+	received := receiver.received()
+	require.Len(t, received, 2)
+	assert.Contains(t, received[1], `"uuid":"3"`)
+}
+
+func TestNewWebhookDestinationInvalidTemplate(t *testing.T) {
+	_, err := NewWebhookDestination(config.WebhookConfig{Template: "{{.Failures"}, "staging", logger.NewUPPLogger("test", "PANIC"))
+	assert.Error(t, err)
+}
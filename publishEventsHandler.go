@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/gorilla/mux"
+)
+
+// loadPublishEvent serves the status of every check scheduled for the publish with the given transaction ID.
+func loadPublishEvent(publishEvents *checks.PublishEvents) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tid := mux.Vars(r)["tid"]
+
+		event, found := publishEvents.Get(tid)
+		if !found {
+			writeJSONError(w, http.StatusNotFound, "no publish found for transaction ID "+tid)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, event)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/content"
+	"github.com/Financial-Times/publish-availability-monitor/envs"
+	"github.com/Financial-Times/publish-availability-monitor/httpcaller"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkedPublishEvents checks the content endpoint in the eu and us environments for three publishes,
+// and returns their publish events once the checks which can end are over:
+// the first one is available in eu but not in us, the second one is still being checked in both,
+// and the third one was updated again since.
+const (
+	publishedUUID = "077f5ac2-0491-420e-a5d0-982e0f86204b"
+	checkedUUID   = "5c0d3f0e-4d6e-4b55-9d73-0b2d8e6b1a7e"
+	updatedUUID   = "88fdde6c-2aa4-4f78-af02-9f680097cfd6"
+)
+
+func checkedPublishEvents(t *testing.T) *checks.PublishEvents {
+	const contentType = "application/vnd.ft-upp-article-internal+json"
+
+	stillChecked := make(chan struct{})
+	readEnv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/validate":
+			w.WriteHeader(http.StatusOK)
+		case "/eu/content/" + publishedUUID:
+			_ = json.NewEncoder(w).Encode(map[string]string{"uuid": publishedUUID, "publishReference": "tid_published"})
+		case "/eu/content/" + checkedUUID, "/us/content/" + checkedUUID:
+			<-stillChecked
+			w.WriteHeader(http.StatusNotFound)
+		case "/eu/content/" + updatedUUID, "/us/content/" + updatedUUID:
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"uuid":             updatedUUID,
+				"publishReference": "tid_later",
+				"lastModified":     time.Now().Add(time.Hour).UTC().Format(checks.DateLayout),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(readEnv.Close)
+
+	environments := envs.NewEnvironments()
+	environments.SetEnvironment("eu", envs.Environment{Name: "eu", ReadURL: readEnv.URL + "/eu"})
+	environments.SetEnvironment("us", envs.Environment{Name: "us", ReadURL: readEnv.URL + "/us"})
+	appConfig := &config.AppConfig{
+		Threshold:           1,
+		ValidationEndpoints: map[string]string{contentType: readEnv.URL + "/validate"},
+		MetricConf: []config.MetricConfig{
+			{Endpoint: "/content/", Granularity: 1, Alias: "content", ContentTypes: []string{contentType}},
+		},
+	}
+
+	log := logger.NewUPPLogger("test", "PANIC")
+	scheduler := checks.NewCheckScheduler(config.SchedulerConfig{}, log)
+	go scheduler.Run()
+	t.Cleanup(scheduler.Stop)
+	t.Cleanup(func() { close(stillChecked) })
+
+	publishEvents := checks.NewPublishEvents(10)
+	deps := checks.SchedulerDeps{
+		MetricSink:    make(chan metrics.PublishMetric, 10),
+		History:       metrics.NewHistory(nil),
+		PublishEvents: publishEvents,
+		InFlight:      checks.NewInFlightChecks(),
+		Scheduler:     scheduler,
+	}
+	endpointChecks := map[string]checks.EndpointSpecificCheck{"content": checks.NewContentCheck(httpcaller.NewCaller(10))}
+
+	publishes := map[string]string{"tid_published": publishedUUID, "tid_checked": checkedUUID, "tid_updated": updatedUUID}
+	for tid, uuid := range publishes {
+		publishedContent := content.GenericContent{UUID: uuid, Type: contentType}
+		p, err := checks.MainPreChecks()[0](context.Background(), publishedContent, tid, time.Now(), appConfig, environments, log)
+		require.NoError(t, err)
+		require.Equal(t, 2, checks.ScheduleChecks(context.Background(), p, endpointChecks, appConfig, deps, nil, log))
+	}
+
+	require.Eventually(t, func() bool {
+		for _, tid := range []string{"tid_published", "tid_updated"} {
+			if event, _ := publishEvents.Get(tid); event.Status == checks.PublishInProgress {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+	return publishEvents
+}
+
+func TestLoadPublishEvent(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/__publishes/{tid}", loadPublishEvent(checkedPublishEvents(t)))
+
+	tests := map[string]struct {
+		TID            string
+		ExpectedUUID   string
+		ExpectedStatus string
+		ExpectedChecks map[string]checks.CheckStatus // by environment
+	}{
+		"available in some environments only": {
+			TID:            "tid_published",
+			ExpectedUUID:   publishedUUID,
+			ExpectedStatus: checks.PublishFailed,
+			ExpectedChecks: map[string]checks.CheckStatus{"eu": checks.CheckSucceeded, "us": checks.CheckFailed},
+		},
+		"still checked": {
+			TID:            "tid_checked",
+			ExpectedUUID:   checkedUUID,
+			ExpectedStatus: checks.PublishInProgress,
+			ExpectedChecks: map[string]checks.CheckStatus{"eu": checks.CheckPending, "us": checks.CheckPending},
+		},
+		"updated since": {
+			TID:            "tid_updated",
+			ExpectedUUID:   updatedUUID,
+			ExpectedStatus: checks.PublishIgnored,
+			ExpectedChecks: map[string]checks.CheckStatus{"eu": checks.CheckIgnored, "us": checks.CheckIgnored},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/__publishes/"+test.TID, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var event checks.PublishEvent
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&event))
+			assert.Equal(t, test.TID, event.TID)
+			assert.Equal(t, test.ExpectedUUID, event.UUID)
+			assert.Equal(t, "application/vnd.ft-upp-article-internal+json", event.ContentType)
+			assert.Equal(t, test.ExpectedStatus, event.Status)
+
+			statuses := make(map[string]checks.CheckStatus)
+			for _, c := range event.Checks {
+				assert.Equal(t, "content", c.EndpointAlias)
+				statuses[c.Environment] = c.Status
+				if c.Status == checks.CheckPending {
+					assert.Nil(t, c.Interval, "a pending check has no publish interval yet")
+				} else {
+					assert.NotNil(t, c.Interval)
+				}
+			}
+			assert.Len(t, event.Checks, len(test.ExpectedChecks), "only the checks of the publish should be listed, once each")
+			assert.Equal(t, test.ExpectedChecks, statuses)
+		})
+	}
+}
+
+func TestLoadPublishEventNotFound(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/__publishes/{tid}", loadPublishEvent(checks.NewPublishEvents(10)))
+
+	req := httptest.NewRequest(http.MethodGet, "/__publishes/tid_unknown", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "tid_unknown")
+}
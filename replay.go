@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/kafka-client-go/v4"
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/envs"
+	"github.com/Financial-Times/publish-availability-monitor/feeds"
+	"github.com/Financial-Times/publish-availability-monitor/httpcaller"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+)
+
+// Message-Timestamp modes of a replay
+const (
+	replayTimestampsOriginal = "original" // the messages are handled one after the other, as published, so the ones past their SLA are skipped
+	replayTimestampsShifted  = "shifted"  // the messages are published again from now, as far apart as they were
+)
+
+// reason of the messages skipped although valid
+const replayNoCheckScheduled = "no check scheduled for the publish"
+
+// recordedMessage is a publish message as consumed, one per line of a replay file.
+type recordedMessage struct {
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	Topic      string            `json:"topic,omitempty"`
+	Partition  int32             `json:"partition,omitempty"`
+	Offset     int64             `json:"offset,omitempty"`
+	ReceivedAt time.Time         `json:"receivedAt"`
+}
+
+// skippedMessage is a replayed message none of whose checks were scheduled.
+type skippedMessage struct {
+	TID    string `json:"transactionId"`
+	Reason string `json:"reason"`
+}
+
+// replayReport describes the publish metrics of the checks of the replayed messages.
+type replayReport struct {
+	Messages       int                     `json:"messages"`
+	Interrupted    int                     `json:"interrupted"` // checks interrupted by a stop of the replay
+	Outcomes       map[string]int          `json:"outcomes"`
+	Skipped        []skippedMessage        `json:"skipped"`
+	PublishMetrics []metrics.PublishMetric `json:"publishMetrics"`
+}
+
+func newReplayReport() *replayReport {
+	return &replayReport{
+		Outcomes:       make(map[string]int),
+		Skipped:        make([]skippedMessage, 0),
+		PublishMetrics: make([]metrics.PublishMetric, 0),
+	}
+}
+
+// skip records msg as skipped for reason.
+func (r *replayReport) skip(msg kafka.FTMessage, reason string) {
+	r.Skipped = append(r.Skipped, skippedMessage{TID: msg.Headers["X-Request-Id"], Reason: reason})
+}
+
+func (r *replayReport) add(pm metrics.PublishMetric) {
+	r.Outcomes[string(pm.GetOutcome())]++
+	r.PublishMetrics = append(r.PublishMetrics, pm)
+}
+
+// readRecordedMessages reads the messages of a replay file, one JSON encoded recordedMessage per line.
+func readRecordedMessages(r io.Reader) ([]recordedMessage, error) {
+	messages := make([]recordedMessage, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var msg recordedMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return nil, fmt.Errorf("cannot decode message on line %d: %w", line, err)
+		}
+		messages = append(messages, msg)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// replayMessages hands the messages to handle in order, until ctx is done, and returns how many were handled.
+// With shifted timestamps, the first message is published now and the next ones as long after it as they were originally,
+// so handle is called at the shifted publish date of each message.
+// The messages without a valid Message-Timestamp are handled as they are.
+func replayMessages(ctx context.Context, messages []recordedMessage, timestamps string, handle func(kafka.FTMessage)) int {
+	var shift time.Duration
+	shifted := false
+
+	replayed := 0
+	for _, m := range messages {
+		headers := make(map[string]string, len(m.Headers))
+		for k, v := range m.Headers {
+			headers[k] = v
+		}
+
+		publishDate, err := time.Parse(checks.DateLayout, headers["Message-Timestamp"])
+		if timestamps == replayTimestampsShifted && err == nil {
+			if !shifted {
+				shift = time.Since(publishDate)
+				shifted = true
+			}
+			publishDate = publishDate.Add(shift)
+			headers["Message-Timestamp"] = publishDate.UTC().Format(checks.DateLayout)
+
+			timer := time.NewTimer(time.Until(publishDate))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return replayed
+			}
+		}
+
+		if ctx.Err() != nil {
+			return replayed
+		}
+		handle(kafka.FTMessage{Headers: headers, Body: m.Body, Topic: m.Topic})
+		replayed++
+	}
+	return replayed
+}
+
+// replay handles the messages of the replay file as if they were consumed, checking the environments
+// of the environments files, and writes the report of the publish metrics once every check is over.
+// The publish metrics are only reported, they are not sent to any destination.
+func replay(appConfig *config.AppConfig, replayFile, timestamps, reportFile string, log *logger.UPPLogger) error {
+	if timestamps != replayTimestampsOriginal && timestamps != replayTimestampsShifted {
+		return fmt.Errorf("unknown replay timestamps [%s], expected %s or %s", timestamps, replayTimestampsOriginal, replayTimestampsShifted)
+	}
+
+	f, err := os.Open(replayFile)
+	if err != nil {
+		return fmt.Errorf("cannot open replay file: %w", err)
+	}
+	messages, err := readRecordedMessages(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("cannot read replay file [%s]: %w", replayFile, err)
+	}
+
+	environments := envs.NewEnvironments()
+	subscribedFeeds := make(map[string][]feeds.Feed)
+	metricSink := make(chan metrics.PublishMetric)
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		envs.WatchConfigFiles(
+			watchCtx,
+			wg,
+			*envsFileName,
+			*envCredentialsFileName,
+			*validatorCredentialsFileName,
+			*configRefreshPeriod,
+			make(map[string]string),
+			environments,
+			subscribedFeeds,
+			appConfig,
+			log,
+		)
+	}()
+	wg.Wait()
+
+	// a replay neither resumes nor checkpoints checks, and waits for all of them to finish
+	schedulerConf := appConfig.SchedulerConf
+	schedulerConf.CheckpointFile = ""
+	schedulerConf.DrainSeconds = math.MaxInt32
+	scheduler := checks.NewCheckScheduler(schedulerConf, log)
+	go scheduler.Run()
+
+	messageHandler := newKafkaMessageHandler(
+		appConfig,
+		environments,
+		subscribedFeeds,
+		MessageHandlerDeps{
+			SchedulerDeps: checks.SchedulerDeps{
+				MetricSink:    metricSink,
+				History:       metrics.NewHistory(make([]metrics.PublishMetric, 0)),
+				PublishEvents: checks.NewPublishEvents(maxPublishEvents),
+				InFlight:      checks.NewInFlightChecks(),
+				Scheduler:     scheduler,
+			},
+			HTTPCaller: httpcaller.NewProtectedCaller(10, httpcaller.NewHostProtection(appConfig.ReadAPIConf)),
+			// the replayed messages are not recorded again
+		},
+		e2eTestUUIDs(appConfig),
+		log,
+	)
+
+	// the publish metrics are added as the checks end, and the skipped messages as they are replayed
+	report := newReplayReport()
+	reportDone := make(chan struct{})
+	go func() {
+		defer close(reportDone)
+		for pm := range metricSink {
+			report.add(pm)
+		}
+	}()
+
+	for !environments.AreReady() {
+		log.Info("Environments not set, retry in 3s...")
+		time.Sleep(3 * time.Second)
+	}
+
+	// a stop interrupts the replay and the checks in progress, and still reports their publish metrics
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Infof("Replaying %d messages with %s timestamps", len(messages), timestamps)
+	replayed := replayMessages(ctx, messages, timestamps, func(msg kafka.FTMessage) {
+		scheduled, err := messageHandler.handleMessage(msg)
+		switch {
+		case err != nil:
+			report.skip(msg, err.Error())
+		case scheduled == 0:
+			report.skip(msg, replayNoCheckScheduled)
+		}
+	})
+
+	log.Infof("Replayed %d messages, waiting for the publish checks in progress", replayed)
+	interrupted := scheduler.Drain(ctx)
+
+	stopWatching()
+	<-watchDone
+	close(metricSink)
+	<-reportDone
+
+	report.Messages = replayed
+	report.Interrupted = interrupted
+	return writeReplayReport(report, reportFile)
+}
+
+// writeReplayReport writes report as JSON to reportFile, or to the standard output if it is empty.
+func writeReplayReport(report *replayReport, reportFile string) (err error) {
+	w := os.Stdout
+	if reportFile != "" {
+		if w, err = os.Create(reportFile); err != nil {
+			return fmt.Errorf("cannot create replay report: %w", err)
+		}
+		defer func() {
+			err = errors.Join(err, w.Close())
+		}()
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(report); err != nil {
+		return fmt.Errorf("cannot write replay report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/kafka-client-go/v4"
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRecordedMessages(t *testing.T) {
+	lines := `{"headers": {"X-Request-Id": "tid_1"}, "body": "{}", "topic": "PreNativeCmsPublicationEvents", "partition": 2, "offset": 42}
+
+{"headers": {"X-Request-Id": "tid_2"}, "body": "{}"}
+`
+	messages, err := readRecordedMessages(strings.NewReader(lines))
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "tid_1", messages[0].Headers["X-Request-Id"])
+	assert.Equal(t, int32(2), messages[0].Partition)
+	assert.Equal(t, int64(42), messages[0].Offset)
+	assert.Equal(t, "tid_2", messages[1].Headers["X-Request-Id"])
+
+	_, err = readRecordedMessages(strings.NewReader(lines + "not json\n"))
+	assert.ErrorContains(t, err, "line 4")
+}
+
+func TestReplayMessagesWithOriginalTimestamps(t *testing.T) {
+	messages := []recordedMessage{
+		{Headers: map[string]string{"X-Request-Id": "tid_1", "Message-Timestamp": "2024-01-02T03:04:05.678Z"}, Body: "1"},
+		{Headers: map[string]string{"X-Request-Id": "tid_2", "Message-Timestamp": "2024-01-02T03:14:05.678Z"}, Body: "2"},
+	}
+
+	var handled []kafka.FTMessage
+	replayed := replayMessages(context.Background(), messages, replayTimestampsOriginal, func(msg kafka.FTMessage) {
+		handled = append(handled, msg)
+	})
+
+	assert.Equal(t, 2, replayed)
+	require.Len(t, handled, 2)
+	assert.Equal(t, messages[0].Headers, handled[0].Headers)
+	assert.Equal(t, "1", handled[0].Body)
+	assert.Equal(t, messages[1].Headers, handled[1].Headers)
+}
+
+func TestReplayMessagesWithShiftedTimestamps(t *testing.T) {
+	messages := []recordedMessage{
+		{Headers: map[string]string{"X-Request-Id": "tid_1", "Message-Timestamp": "2024-01-02T03:04:05.000Z"}},
+		{Headers: map[string]string{"X-Request-Id": "tid_2", "Message-Timestamp": "2024-01-02T03:04:05.200Z"}},
+		{Headers: map[string]string{"X-Request-Id": "tid_3"}},
+	}
+
+	start := time.Now()
+	var handled []kafka.FTMessage
+	var handledAt []time.Time
+	replayed := replayMessages(context.Background(), messages, replayTimestampsShifted, func(msg kafka.FTMessage) {
+		handled = append(handled, msg)
+		handledAt = append(handledAt, time.Now())
+	})
+
+	assert.Equal(t, 3, replayed)
+	require.Len(t, handled, 3)
+
+	first, err := time.Parse(checks.DateLayout, handled[0].Headers["Message-Timestamp"])
+	require.NoError(t, err)
+	second, err := time.Parse(checks.DateLayout, handled[1].Headers["Message-Timestamp"])
+	require.NoError(t, err)
+	assert.WithinDuration(t, start, first, 100*time.Millisecond)
+	assert.Equal(t, 200*time.Millisecond, second.Sub(first))
+	assert.GreaterOrEqual(t, handledAt[1].Sub(handledAt[0]), 150*time.Millisecond, "the messages should be replayed as far apart as they were published")
+	assert.Empty(t, handled[2].Headers["Message-Timestamp"])
+
+	assert.Equal(t, "2024-01-02T03:04:05.000Z", messages[0].Headers["Message-Timestamp"], "the recorded messages should not change")
+}
+
+func TestReplayMessagesStopsWhenCancelled(t *testing.T) {
+	messages := []recordedMessage{
+		{Headers: map[string]string{"X-Request-Id": "tid_1", "Message-Timestamp": "2024-01-02T03:04:05.000Z"}},
+		{Headers: map[string]string{"X-Request-Id": "tid_2", "Message-Timestamp": "2024-01-02T04:04:05.000Z"}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	replayed := replayMessages(ctx, messages, replayTimestampsShifted, func(kafka.FTMessage) {
+		cancel()
+	})
+
+	assert.Equal(t, 1, replayed)
+}
+
+func TestWriteReplayReport(t *testing.T) {
+	report := newReplayReport()
+	report.Messages = 1
+	report.add(metrics.PublishMetric{TID: "tid_1", Outcome: metrics.OutcomeSuccess, PublishOK: true})
+	report.add(metrics.PublishMetric{TID: "tid_1", Outcome: metrics.OutcomeFailure})
+	report.add(metrics.PublishMetric{TID: "tid_1", Outcome: metrics.OutcomeFailure})
+
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, writeReplayReport(report, reportFile))
+
+	data, err := os.ReadFile(reportFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"messages": 1`)
+	assert.Contains(t, string(data), `"success": 1`)
+	assert.Contains(t, string(data), `"failure": 2`)
+	assert.Equal(t, 3, strings.Count(string(data), `"transactionId": "tid_1"`))
+}
+
+// writeReplayFixtures writes the environments and credentials files of a read environment at readURL,
+// and points the flags of the monitor at them.
+func writeReplayFixtures(t *testing.T, readURL string) {
+	dir := t.TempDir()
+	files := map[*string]string{
+		envsFileName:                 fmt.Sprintf(`[{"name": "test-env", "read-url": "%s"}]`, readURL),
+		envCredentialsFileName:       `[{"env-name": "test-env", "username": "test-user", "password": "test-pwd"}]`,
+		validatorCredentialsFileName: `{"username": "test-user", "password": "test-pwd"}`,
+	}
+	for fileName, data := range files {
+		fileName, previous := fileName, *fileName
+		t.Cleanup(func() { *fileName = previous })
+
+		f, err := os.CreateTemp(dir, "*.json")
+		require.NoError(t, err)
+		_, err = f.WriteString(data)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		*fileName = f.Name()
+	}
+}
+
+func TestReplay(t *testing.T) {
+	const contentUUID = "077f5ac2-0491-420e-a5d0-982e0f86204b"
+	const contentType = "application/vnd.ft-upp-article-internal+json"
+
+	readEnv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/validate":
+			w.WriteHeader(http.StatusOK)
+		case "/content/" + contentUUID:
+			_ = json.NewEncoder(w).Encode(map[string]string{"uuid": contentUUID, "publishReference": "tid_replayed"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer readEnv.Close()
+	writeReplayFixtures(t, readEnv.URL)
+
+	appConfig := &config.AppConfig{
+		Threshold:           5,
+		ValidationEndpoints: map[string]string{contentType: readEnv.URL + "/validate"},
+		MetricConf: []config.MetricConfig{
+			{Endpoint: "/content/", Granularity: 1, Alias: "content", ContentTypes: []string{contentType}},
+		},
+	}
+	log := logger.NewUPPLogger("test", "PANIC")
+
+	// published long ago, and recorded along with a synthetic publish
+	var recorded strings.Builder
+	for _, tid := range []string{"tid_replayed", "SYNTHETIC-REQ-MON_replayed"} {
+		line, err := json.Marshal(recordedMessage{
+			Headers: map[string]string{
+				"X-Request-Id":      tid,
+				"Origin-System-Id":  "http://cmdb.ft.com/systems/cct",
+				"Content-Type":      contentType,
+				"Message-Timestamp": "2024-01-02T03:04:05.678Z",
+			},
+			Body: fmt.Sprintf(`{"uuid": "%s"}`, contentUUID),
+		})
+		require.NoError(t, err)
+		recorded.Write(append(line, '\n'))
+	}
+	replayFile := filepath.Join(t.TempDir(), "messages.jsonl")
+	require.NoError(t, os.WriteFile(replayFile, []byte(recorded.String()), 0o600))
+
+	tests := map[string]struct {
+		Timestamps       string
+		ExpectedOutcomes map[string]int
+		ExpectedSkipped  []skippedMessage
+	}{
+		"shifted timestamps": {
+			Timestamps:       replayTimestampsShifted,
+			ExpectedOutcomes: map[string]int{"success": 1},
+			ExpectedSkipped:  []skippedMessage{{TID: "SYNTHETIC-REQ-MON_replayed", Reason: "ignorable message"}},
+		},
+		"original timestamps": {
+			Timestamps:       replayTimestampsOriginal,
+			ExpectedOutcomes: map[string]int{},
+			ExpectedSkipped: []skippedMessage{
+				{TID: "tid_replayed", Reason: "publish not checked: the content is past its publish SLA"},
+				{TID: "SYNTHETIC-REQ-MON_replayed", Reason: "ignorable message"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reportFile := filepath.Join(t.TempDir(), "report.json")
+			require.NoError(t, replay(appConfig, replayFile, test.Timestamps, reportFile, log))
+
+			data, err := os.ReadFile(reportFile)
+			require.NoError(t, err)
+			var report replayReport
+			require.NoError(t, json.Unmarshal(data, &report))
+
+			assert.Equal(t, 2, report.Messages)
+			assert.Zero(t, report.Interrupted)
+			assert.Equal(t, test.ExpectedOutcomes, report.Outcomes)
+			assert.Equal(t, test.ExpectedSkipped, report.Skipped)
+			for _, pm := range report.PublishMetrics {
+				assert.Equal(t, "tid_replayed", pm.TID)
+				assert.Equal(t, contentUUID, pm.UUID)
+				assert.Equal(t, "test-env", pm.Platform)
+				assert.True(t, pm.PublishOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+)
+
+const slaFormatCSV = "csv"
+
+// loadSLA serves the SLA compliance over the window query parameter, or every configured window,
+// grouped by the comma separated dimensions in groupBy (all of them by default),
+// as JSON or, if format is csv, as CSV.
+func loadSLA(sla *metrics.SLA) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		windows := sla.Windows()
+		if window := params.Get("window"); window != "" {
+			windows = []string{window}
+		}
+
+		groupBy := metrics.SLADimensions
+		if params.Has("groupBy") {
+			groupBy = nil
+			for _, d := range strings.Split(params.Get("groupBy"), ",") {
+				if d = strings.TrimSpace(d); d != "" {
+					groupBy = append(groupBy, d)
+				}
+			}
+		}
+
+		reports := make([]metrics.SLAReport, 0, len(windows))
+		for _, window := range windows {
+			report, err := sla.Report(window, groupBy)
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			reports = append(reports, report)
+		}
+
+		if params.Get("format") == slaFormatCSV {
+			writeSLACSV(w, reports, groupBy)
+			return
+		}
+		writeJSON(w, http.StatusOK, reports)
+	}
+}
+
+// writeSLACSV writes a row per window and group, with a column per grouping dimension.
+func writeSLACSV(w http.ResponseWriter, reports []metrics.SLAReport, groupBy []string) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="sla.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	header := append([]string{"window", "from", "to"}, groupBy...)
+	_ = cw.Write(append(header, "total", "succeeded", "failed", "compliance", "inconclusive"))
+
+	for _, report := range reports {
+		for _, row := range report.Rows {
+			record := []string{report.Window, report.From.Format(time.RFC3339), report.To.Format(time.RFC3339)}
+			for _, d := range groupBy {
+				record = append(record, row.Dimension(d))
+			}
+			record = append(record,
+				strconv.Itoa(row.Total),
+				strconv.Itoa(row.Succeeded),
+				strconv.Itoa(row.Failed),
+				strconv.FormatFloat(row.Compliance, 'f', 2, 64),
+				strconv.Itoa(row.Inconclusive),
+			)
+			_ = cw.Write(record)
+		}
+	}
+	cw.Flush()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSLA(t *testing.T) *metrics.SLA {
+	sla, err := metrics.NewSLA(config.SLAConfig{Windows: []string{"1h", "24h"}})
+	require.NoError(t, err)
+
+	now := time.Now()
+	for i, publishOK := range []bool{true, true, true, false} {
+		sla.Send(metrics.PublishMetric{
+			ContentType: "application/vnd.ft-upp-article+json",
+			PublishOK:   publishOK,
+			PublishDate: now.Add(-time.Duration(i) * time.Minute),
+			Platform:    "eu",
+			Config:      config.MetricConfig{Alias: "content"},
+		})
+	}
+	return sla
+}
+
+func TestLoadSLA(t *testing.T) {
+	tests := map[string]struct {
+		Query           string
+		ExpectedStatus  int
+		ExpectedWindows []string
+		ExpectedRows    int
+	}{
+		"all windows": {
+			Query:           "",
+			ExpectedStatus:  http.StatusOK,
+			ExpectedWindows: []string{"1h", "24h"},
+			ExpectedRows:    1,
+		},
+		"single window": {
+			Query:           "?window=24h&groupBy=environment,endpoint",
+			ExpectedStatus:  http.StatusOK,
+			ExpectedWindows: []string{"24h"},
+			ExpectedRows:    1,
+		},
+		"overall": {
+			Query:           "?window=1h&groupBy=",
+			ExpectedStatus:  http.StatusOK,
+			ExpectedWindows: []string{"1h"},
+			ExpectedRows:    1,
+		},
+		"unknown window": {
+			Query:          "?window=7d",
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		"unknown dimension": {
+			Query:          "?groupBy=publication",
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	handler := loadSLA(newTestSLA(t))
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, "/__sla"+test.Query, nil))
+
+			assert.Equal(t, test.ExpectedStatus, w.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				return
+			}
+
+			var reports []metrics.SLAReport
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&reports))
+			var windows []string
+			for _, report := range reports {
+				windows = append(windows, report.Window)
+				assert.Len(t, report.Rows, test.ExpectedRows)
+				assert.Equal(t, 75.0, report.Rows[0].Compliance)
+			}
+			assert.Equal(t, test.ExpectedWindows, windows)
+		})
+	}
+}
+
+func TestLoadSLACSV(t *testing.T) {
+	w := httptest.NewRecorder()
+	loadSLA(newTestSLA(t))(w, httptest.NewRequest(http.MethodGet, "/__sla?format=csv&groupBy=endpoint,environment", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "window,from,to,endpoint,environment,total,succeeded,failed,compliance,inconclusive", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "1h,"))
+	assert.True(t, strings.HasSuffix(lines[1], ",content,eu,4,3,1,75.00,0"))
+	assert.True(t, strings.HasPrefix(lines[2], "24h,"))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/kafka-client-go/v4"
+	"github.com/Financial-Times/publish-availability-monitor/checks"
+	"github.com/Financial-Times/publish-availability-monitor/config"
+	"github.com/Financial-Times/publish-availability-monitor/envs"
+	"github.com/Financial-Times/publish-availability-monitor/feeds"
+	"github.com/Financial-Times/publish-availability-monitor/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupTracingDisabled(t *testing.T) {
+	shutdown, err := setupTracing(config.TracingConfig{})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetupTracingUnknownExporter(t *testing.T) {
+	_, err := setupTracing(config.TracingConfig{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
+
+func TestSetupTracingFileExporterRecordsMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := setupTracing(config.TracingConfig{Exporter: tracingExporterFile, FilePath: path})
+	require.NoError(t, err)
+
+	mh := NewKafkaMessageHandler(
+		&config.AppConfig{},
+		envs.NewEnvironments(),
+		map[string][]feeds.Feed{},
+		MessageHandlerDeps{
+			SchedulerDeps: checks.SchedulerDeps{
+				MetricSink:    make(chan metrics.PublishMetric),
+				History:       metrics.NewHistory(make([]metrics.PublishMetric, 0)),
+				PublishEvents: checks.NewPublishEvents(10),
+				InFlight:      checks.NewInFlightChecks(),
+			},
+		},
+		nil,
+		logger.NewUPPLogger("test", "PANIC"),
+	)
+	mh.HandleMessage(kafka.FTMessage{
+		Headers: map[string]string{"X-Request-Id": "SYNTHETIC-REQ-MON_tracing"},
+	})
+
+	require.NoError(t, shutdown(context.Background()))
+
+	traces, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(traces), `"Name":"HandleMessage"`)
+	assert.Contains(t, string(traces), "SYNTHETIC-REQ-MON_tracing")
+}